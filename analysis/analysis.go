@@ -0,0 +1,76 @@
+// Package analysis turns text into terms. The same analysis is used when
+// pages are indexed or classified and when queries are parsed, so that words
+// in queries match words in pages.
+package analysis
+
+const (
+	// Longer tokens are dropped by the default analyzer. They are usually
+	// not words, but things like encoded data.
+	MAX_TOKEN_LENGTH = 64
+
+	DEFAULT_ANALYZER = "default"
+)
+
+var (
+	// Default analyzer folds case and accents and drops very long tokens.
+	Default = Analyzer{Filters: []Filter{FoldCase, FoldAccents, MaxLength(MAX_TOKEN_LENGTH)}}
+
+	// English analyzer also removes stopwords and stems words.
+	English = Analyzer{Filters: []Filter{
+		FoldCase, FoldAccents, RemoveStopwords(EnglishStopwords), Stem,
+		MinLength(2), MaxLength(MAX_TOKEN_LENGTH),
+	}}
+
+	// Analyzers by their names, which are used to choose an analyzer in
+	// flags and to remember it in stored indexes.
+	Analyzers = map[string]Analyzer{
+		DEFAULT_ANALYZER: Default,
+		"english":        English,
+	}
+)
+
+// Filter transforms a single term. Terms for which it returns an empty string
+// are removed.
+type Filter func(term string) string
+
+// Analyzer splits text into tokens with Tokenize and passes each of them
+// through a chain of filters.
+type Analyzer struct {
+	Filters []Filter
+}
+
+// With returns a copy of the analyzer with more filters at the end of the
+// chain.
+func (a Analyzer) With(filters ...Filter) Analyzer {
+	chain := make([]Filter, 0, len(a.Filters)+len(filters))
+	chain = append(chain, a.Filters...)
+	return Analyzer{Filters: append(chain, filters...)}
+}
+
+// Analyze returns tokens of a text. Text of each token is the resulting
+// term, while its offsets point to the original word.
+func (a Analyzer) Analyze(text string) []Token {
+	tokens := Tokenize(text)
+	result := tokens[:0]
+	for _, t := range tokens {
+		for _, f := range a.Filters {
+			if t.Text = f(t.Text); t.Text == "" {
+				break
+			}
+		}
+		if t.Text != "" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// Terms returns terms of a text in the order they appear.
+func (a Analyzer) Terms(text string) []string {
+	tokens := a.Analyze(text)
+	terms := make([]string, len(tokens))
+	for i, t := range tokens {
+		terms[i] = t.Text
+	}
+	return terms
+}
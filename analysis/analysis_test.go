@@ -0,0 +1,92 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := map[string][]string{
+		"Hello, world!":     {"Hello", "world"},
+		"C++ and C# or a+b": {"C++", "and", "C#", "or", "a", "b"},
+		"Don't stop":        {"Don't", "stop"},
+		"It’s 'quoted'":     {"It's", "quoted"},
+		"Test!123":          {"Test", "123"},
+		"Ελληνικά και русский язык": {"Ελληνικά", "και", "русский", "язык"},
+	}
+	for text, expected := range cases {
+		tokens := Tokenize(text)
+		words := make([]string, len(tokens))
+		for i, token := range tokens {
+			words[i] = token.Text
+		}
+		if !reflect.DeepEqual(words, expected) {
+			t.Errorf("Expected %q for %q, got %q", expected, text, words)
+		}
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	cases := []struct {
+		analyzer Analyzer
+		text     string
+		expected []string
+	}{
+		{Default, "Crème Brûlée in C++", []string{"creme", "brulee", "in", "c++"}},
+		{Default, "STRASSE Straße", []string{"strasse", "strasse"}},
+		{Default, "Cafe\u0301 नमस्ते हिन्दी", []string{"cafe", "नमस्ते", "हिन्दी"}},
+		{Default, "ที่นี่", []string{"ที่นี่"}},
+		{English, "The crawlers are crawling the web", []string{"crawler", "crawl", "web"}},
+	}
+	for _, c := range cases {
+		if terms := c.analyzer.Terms(c.text); !reflect.DeepEqual(terms, c.expected) {
+			t.Errorf("Expected %q for %q, got %q", c.expected, c.text, terms)
+		}
+	}
+
+	tokens := Default.Analyze("Hello, Wörld")
+	if tokens[1].Text != "world" || tokens[1].Start != 7 || tokens[1].End != 13 {
+		t.Errorf("Unexpected token %+v", tokens[1])
+	}
+}
+
+func TestStem(t *testing.T) {
+	cases := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"crawling":       "crawl",
+		"hopping":        "hop",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"generalization": "gener",
+		"adjustment":     "adjust",
+		"controlling":    "control",
+	}
+	for word, stem := range cases {
+		if Stem(word) != stem {
+			t.Errorf("Expected %q for %q, got %q", stem, word, Stem(word))
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		max      int
+		expected int
+	}{
+		{"crawler", "crawler", 2, 0},
+		{"crawlr", "crawler", 2, 1},
+		{"crawler", "cralwer", 2, 2},
+		{"kitten", "sitting", 5, 3},
+		{"kitten", "sitting", 2, 3},
+		{"crawler", "c", 2, 3},
+		{"café", "cafe", 1, 1},
+	}
+	for _, c := range cases {
+		if d := EditDistance(c.a, c.b, c.max); d != c.expected {
+			t.Errorf("Expected %d for %q and %q with max %d, got %d", c.expected, c.a, c.b, c.max, d)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Replacements for letters with diacritics and ligatures. Letters that are
+// written as a base letter followed by combining marks are handled by
+// removing the marks.
+var accentReplacer = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "ā", "a", "ă", "a", "ą", "a",
+	"ç", "c", "ć", "c", "ĉ", "c", "ċ", "c", "č", "c",
+	"ď", "d", "đ", "d", "ð", "d",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ē", "e", "ĕ", "e", "ė", "e", "ę", "e", "ě", "e",
+	"ĝ", "g", "ğ", "g", "ġ", "g", "ģ", "g",
+	"ĥ", "h", "ħ", "h",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ĩ", "i", "ī", "i", "ĭ", "i", "į", "i", "ı", "i",
+	"ĵ", "j", "ķ", "k",
+	"ĺ", "l", "ļ", "l", "ľ", "l", "ŀ", "l", "ł", "l",
+	"ñ", "n", "ń", "n", "ņ", "n", "ň", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "ō", "o", "ŏ", "o", "ő", "o",
+	"ŕ", "r", "ŗ", "r", "ř", "r",
+	"ś", "s", "ŝ", "s", "ş", "s", "š", "s", "ș", "s",
+	"ţ", "t", "ť", "t", "ŧ", "t", "ț", "t",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ũ", "u", "ū", "u", "ŭ", "u", "ů", "u", "ű", "u", "ų", "u",
+	"ŵ", "w", "ý", "y", "ÿ", "y", "ŷ", "y",
+	"ź", "z", "ż", "z", "ž", "z",
+	"ß", "ss", "æ", "ae", "œ", "oe", "þ", "th",
+)
+
+// FoldCase converts a term to a form that can be used for case insensitive
+// comparison. Unlike strings.ToLower it also handles letters like the final
+// sigma, which have more than one lower case form.
+func FoldCase(term string) string {
+	return strings.Map(func(r rune) rune {
+		return unicode.ToLower(unicode.ToUpper(r))
+	}, term)
+}
+
+// FoldAccents removes diacritics from Latin letters, so that "café" matches
+// "cafe". Terms need to be in lower case. Combining marks are only removed
+// after Latin letters, in other scripts like Devanagari they are vowels and
+// removing them changes words.
+func FoldAccents(term string) string {
+	term = accentReplacer.Replace(term)
+	// Whether the last letter that isn't a mark is Latin
+	latin := false
+	return strings.Map(func(r rune) rune {
+		if !unicode.Is(unicode.Mn, r) {
+			latin = unicode.Is(unicode.Latin, r)
+			return r
+		}
+		if latin {
+			return -1
+		}
+		return r
+	}, term)
+}
+
+// MinLength creates a filter that removes terms shorter than a number of
+// characters.
+func MinLength(length int) Filter {
+	return func(term string) string {
+		if utf8.RuneCountInString(term) < length {
+			return ""
+		}
+		return term
+	}
+}
+
+// MaxLength creates a filter that removes terms longer than a number of
+// characters.
+func MaxLength(length int) Filter {
+	return func(term string) string {
+		if utf8.RuneCountInString(term) > length {
+			return ""
+		}
+		return term
+	}
+}
+
+// RemoveStopwords creates a filter that removes common words. Stopwords need
+// to be in the same form as terms that reach the filter.
+func RemoveStopwords(stopwords []string) Filter {
+	set := make(map[string]bool, len(stopwords))
+	for _, w := range stopwords {
+		set[w] = true
+	}
+	return func(term string) string {
+		if set[term] {
+			return ""
+		}
+		return term
+	}
+}
@@ -0,0 +1,222 @@
+package analysis
+
+import (
+	"strings"
+)
+
+// Stem reduces an English word to its stem using the Porter stemming
+// algorithm (see https://tartarus.org/martin/PorterStemmer/def.txt), so that
+// words like "crawling", "crawled" and "crawls" become "crawl".
+//
+// The word is expected to be in lower case. Words that contain anything other
+// than ASCII letters are returned as is.
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+	s := &stemmer{b: []byte(word)}
+	s.step1a()
+	s.step1b()
+	s.step1c()
+	s.step2()
+	s.step3()
+	s.step4()
+	s.step5()
+	return string(s.b)
+}
+
+type stemmer struct {
+	b []byte
+}
+
+// isConsonant reports whether the letter at position i is a consonant.
+func (s *stemmer) isConsonant(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.isConsonant(i-1)
+	}
+	return true
+}
+
+// measure returns the number of vowel-consonant sequences in the first n
+// letters of the word.
+func (s *stemmer) measure(n int) int {
+	m := 0
+	i := 0
+	for i < n && s.isConsonant(i) {
+		i++
+	}
+	for i < n {
+		for i < n && !s.isConsonant(i) {
+			i++
+		}
+		if i >= n {
+			break
+		}
+		for i < n && s.isConsonant(i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+func (s *stemmer) hasVowel(n int) bool {
+	for i := 0; i < n; i++ {
+		if !s.isConsonant(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsDoubleConsonant reports whether the first n letters end with a double
+// consonant.
+func (s *stemmer) endsDoubleConsonant(n int) bool {
+	return n >= 2 && s.b[n-1] == s.b[n-2] && s.isConsonant(n-1)
+}
+
+// endsCVC reports whether the first n letters end with consonant-vowel-
+// consonant sequence where the last consonant is not w, x or y.
+func (s *stemmer) endsCVC(n int) bool {
+	if n < 3 || !s.isConsonant(n-1) || s.isConsonant(n-2) || !s.isConsonant(n-3) {
+		return false
+	}
+	c := s.b[n-1]
+	return c != 'w' && c != 'x' && c != 'y'
+}
+
+func (s *stemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.b), suffix)
+}
+
+// replace replaces the suffix with a replacement if the measure of the
+// remaining stem is greater than minMeasure.
+func (s *stemmer) replace(suffix, replacement string, minMeasure int) bool {
+	if !s.hasSuffix(suffix) {
+		return false
+	}
+	stem := len(s.b) - len(suffix)
+	if s.measure(stem) > minMeasure {
+		s.b = append(s.b[:stem], replacement...)
+	}
+	return true
+}
+
+func (s *stemmer) step1a() {
+	switch {
+	case s.hasSuffix("sses"):
+		s.b = s.b[:len(s.b)-2]
+	case s.hasSuffix("ies"):
+		s.b = s.b[:len(s.b)-2]
+	case s.hasSuffix("ss"):
+	case s.hasSuffix("s"):
+		s.b = s.b[:len(s.b)-1]
+	}
+}
+
+func (s *stemmer) step1b() {
+	if s.hasSuffix("eed") {
+		if s.measure(len(s.b)-3) > 0 {
+			s.b = s.b[:len(s.b)-1]
+		}
+		return
+	}
+	var stem int
+	switch {
+	case s.hasSuffix("ed") && s.hasVowel(len(s.b)-2):
+		stem = len(s.b) - 2
+	case s.hasSuffix("ing") && s.hasVowel(len(s.b)-3):
+		stem = len(s.b) - 3
+	default:
+		return
+	}
+	s.b = s.b[:stem]
+	switch {
+	case s.hasSuffix("at"), s.hasSuffix("bl"), s.hasSuffix("iz"):
+		s.b = append(s.b, 'e')
+	case s.endsDoubleConsonant(len(s.b)):
+		if c := s.b[len(s.b)-1]; c != 'l' && c != 's' && c != 'z' {
+			s.b = s.b[:len(s.b)-1]
+		}
+	case s.measure(len(s.b)) == 1 && s.endsCVC(len(s.b)):
+		s.b = append(s.b, 'e')
+	}
+}
+
+func (s *stemmer) step1c() {
+	if s.hasSuffix("y") && s.hasVowel(len(s.b)-1) {
+		s.b[len(s.b)-1] = 'i'
+	}
+}
+
+var step2Suffixes = [][2]string{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"},
+	{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+	{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+	{"logi", "log"},
+}
+
+func (s *stemmer) step2() {
+	for _, r := range step2Suffixes {
+		if s.replace(r[0], r[1], 0) {
+			return
+		}
+	}
+}
+
+var step3Suffixes = [][2]string{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+func (s *stemmer) step3() {
+	for _, r := range step3Suffixes {
+		if s.replace(r[0], r[1], 0) {
+			return
+		}
+	}
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+func (s *stemmer) step4() {
+	for _, suffix := range step4Suffixes {
+		if !s.hasSuffix(suffix) {
+			continue
+		}
+		stem := len(s.b) - len(suffix)
+		if suffix == "ion" && (stem == 0 || (s.b[stem-1] != 's' && s.b[stem-1] != 't')) {
+			return
+		}
+		if s.measure(stem) > 1 {
+			s.b = s.b[:stem]
+		}
+		return
+	}
+}
+
+func (s *stemmer) step5() {
+	if s.hasSuffix("e") {
+		stem := len(s.b) - 1
+		m := s.measure(stem)
+		if m > 1 || (m == 1 && !s.endsCVC(stem)) {
+			s.b = s.b[:stem]
+		}
+	}
+	if s.hasSuffix("ll") && s.measure(len(s.b)) > 1 {
+		s.b = s.b[:len(s.b)-1]
+	}
+}
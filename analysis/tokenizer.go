@@ -0,0 +1,93 @@
+package analysis
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a word in a text.
+type Token struct {
+	Text string
+	// Byte offsets of the word in the text
+	Start, End int
+}
+
+// Tokenize splits text into words. Words consist of letters, digits and
+// combining marks. All other characters are treated as separators, except
+// for:
+//
+//   - apostrophes between letters, so "don't" stays a single word (typographic
+//     apostrophes are replaced with plain ones);
+//   - up to two "+" or "#" signs after a letter at the end of a word, so that
+//     names like "C++" and "C#" are not reduced to "c".
+func Tokenize(text string) []Token {
+	tokens := make([]Token, 0)
+	start := -1
+	var prev rune
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case isWordRune(r):
+			if start < 0 {
+				start = i
+			}
+		case start >= 0 && isApostrophe(r) && unicode.IsLetter(prev) && unicode.IsLetter(runeAt(text, i+size)):
+		default:
+			if start >= 0 {
+				end := i
+				if unicode.IsLetter(prev) {
+					end = symbolSuffixEnd(text, i)
+				}
+				tokens = append(tokens, newToken(text, start, end))
+				start = -1
+				if end > i {
+					i, prev = end, 0
+					continue
+				}
+			}
+		}
+		prev = r
+		i += size
+	}
+	if start >= 0 {
+		tokens = append(tokens, newToken(text, start, len(text)))
+	}
+	return tokens
+}
+
+func newToken(text string, start, end int) Token {
+	word := strings.ReplaceAll(text[start:end], "’", "'")
+	return Token{Text: word, Start: start, End: end}
+}
+
+// symbolSuffixEnd returns the end of "+" or "#" signs that make a part of a
+// word, like in "C++", starting from a given offset. If there are no such
+// signs, the offset is returned.
+func symbolSuffixEnd(text string, i int) int {
+	end := i
+	for end < len(text) && end-i < 2 && (text[end] == '+' || text[end] == '#') {
+		end++
+	}
+	if end < len(text) && isWordRune(runeAt(text, end)) {
+		// Something like "a+b"
+		return i
+	}
+	return end
+}
+
+func runeAt(text string, i int) rune {
+	if i >= len(text) {
+		return utf8.RuneError
+	}
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return r
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’'
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"go.roman.zone/crawl/index"
+	"go.roman.zone/crawl/index/facet"
+	"net/http"
+	"time"
+)
+
+// Parameters of search requests that filter results
+const (
+	FILTER_HOST = "host"
+	FILTER_LANG = "lang"
+	FILTER_TYPE = "type"
+	FILTER_FROM = "from"
+	FILTER_TO   = "to"
+)
+
+type FacetCountOutput struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type DateFacetOutput struct {
+	Bucket string    `json:"bucket"`
+	Since  time.Time `json:"since"`
+	Count  int       `json:"count"`
+}
+
+type FacetsOutput struct {
+	Hosts        []FacetCountOutput `json:"hosts"`
+	Languages    []FacetCountOutput `json:"languages"`
+	ContentTypes []FacetCountOutput `json:"content_types"`
+	Dates        []DateFacetOutput  `json:"dates"`
+}
+
+// parseFilters creates filters from parameters of a request. Hosts include
+// their subdomains and dates can be either like "2006-01-02" or in RFC 3339
+// format. A "to" date includes the whole day.
+func parseFilters(r *http.Request) ([]index.Query, error) {
+	values := r.URL.Query()
+	filters := make([]index.Query, 0)
+	if host := values.Get(FILTER_HOST); host != "" {
+		filters = append(filters, index.Site{Host: host})
+	}
+	if lang := values.Get(FILTER_LANG); lang != "" {
+		filters = append(filters, index.Language{Code: lang})
+	}
+	if contentType := values.Get(FILTER_TYPE); contentType != "" {
+		filters = append(filters, index.ContentType{Type: contentType})
+	}
+	var dates index.FetchedBetween
+	var err error
+	if s := values.Get(FILTER_FROM); s != "" {
+		if dates.From, err = index.ParseTime(s); err != nil {
+			return nil, requestError{err: err}
+		}
+	}
+	if s := values.Get(FILTER_TO); s != "" {
+		if dates.To, err = index.ParseEndTime(s); err != nil {
+			return nil, requestError{err: err}
+		}
+	}
+	if !dates.From.IsZero() || !dates.To.IsZero() {
+		filters = append(filters, dates)
+	}
+	return filters, nil
+}
+
+func facetsOutput(hits []index.Hit) FacetsOutput {
+	docs := make([]index.Document, len(hits))
+	for i, hit := range hits {
+		docs[i] = hit.Document
+	}
+	facets := facet.Compute(docs, time.Now(), facet.DEFAULT_SIZE)
+	output := FacetsOutput{
+		Hosts:        countsOutput(facets.Hosts),
+		Languages:    countsOutput(facets.Languages),
+		ContentTypes: countsOutput(facets.ContentTypes),
+		Dates:        make([]DateFacetOutput, len(facets.Dates)),
+	}
+	for i, d := range facets.Dates {
+		output.Dates[i] = DateFacetOutput{Bucket: d.Bucket, Since: d.Since, Count: d.Count}
+	}
+	return output
+}
+
+func countsOutput(counts []facet.Count) []FacetCountOutput {
+	output := make([]FacetCountOutput, len(counts))
+	for i, c := range counts {
+		output[i] = FacetCountOutput{Value: c.Value, Count: c.Count}
+	}
+	return output
+}
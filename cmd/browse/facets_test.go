@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"go.roman.zone/crawl/analysis"
+	"go.roman.zone/crawl/index"
+	"go.roman.zone/crawl/index/ranking"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestFilters(t *testing.T) {
+	idx := index.NewMemoryIndex(analysis.Default)
+	defer idx.Close()
+	day := time.Date(2024, 1, 5, 15, 0, 0, 0, time.UTC)
+	pages := []index.Page{
+		{URL: url.URL{Scheme: "https", Host: "a.example.com"}, Language: "en", ContentType: "text/html", FetchTime: day.Add(-24 * time.Hour)},
+		{URL: url.URL{Scheme: "https", Host: "b.example.com"}, Language: "de", ContentType: "text/html", FetchTime: day},
+		{URL: url.URL{Scheme: "https", Host: "other.com"}, Language: "en", ContentType: "text/plain", FetchTime: day.Add(24 * time.Hour)},
+	}
+	for _, page := range pages {
+		page.Content = "page"
+		if err := idx.Add(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	router := makeRouter(&server{index: idx, ranker: ranking.PageRankBlend{Relevance: ranking.BM25{K1: ranking.DEFAULT_K1, B: ranking.DEFAULT_B}}})
+
+	cases := []struct {
+		filters  string
+		expected int
+	}{
+		{"", 3},
+		{"host=example.com", 2},
+		{"lang=en", 2},
+		{"type=text/html", 2},
+		{"type=text", 3},
+		{"from=2024-01-05", 2},
+		// Dates include the whole day
+		{"to=2024-01-05", 2},
+		{"from=2024-01-05&to=2024-01-05", 1},
+		{"to=2024-01-05T15:00:00Z", 1},
+		{"lang=en&type=text/plain", 1},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/search?q=page&"+c.filters, nil))
+		var output SearchOutput
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Fatalf("Can't decode response for %q: %v", c.filters, err)
+		}
+		if output.Total != c.expected {
+			t.Errorf("Expected %d results for %q, got %d", c.expected, c.filters, output.Total)
+		}
+	}
+
+	for _, filters := range []string{"from=yesterday", "to=2024-13-01", "from=2024-01-05&to=05.01.2024"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/search?q=page&"+filters, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %q, got %d", http.StatusBadRequest, filters, w.Code)
+		}
+	}
+}
@@ -1,100 +1,79 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"github.com/gorilla/mux"
 	"go.roman.zone/crawl/index"
+	"go.roman.zone/crawl/index/ranking"
+	"go.roman.zone/crawl/index/snippet"
 	"log"
 	"net/http"
-	"sort"
-	"strings"
+	"os"
 )
 
 var (
-	listenHost = flag.String("host", "127.0.0.1", "Host to listen on")
-	listenPort = flag.Int("port", 8080, "Port to listen on")
+	listenHost     = flag.String("host", "127.0.0.1", "Host to listen on")
+	listenPort     = flag.Int("port", 8080, "Port to listen on")
+	indexDir       = flag.String("index", index.STORAGE_DIR, "Directory with the index")
+	bm25K1         = flag.Float64("k1", ranking.DEFAULT_K1, "BM25 k1 parameter (keyword frequency saturation)")
+	bm25B          = flag.Float64("b", ranking.DEFAULT_B, "BM25 b parameter (document length normalization)")
+	boostsStr      = flag.String("boosts", "", "Comma-separated weights of fields that override the default ones, e.g. \"title:3,url:2\"")
+	pageRankWeight = flag.Float64("pagerank-weight", ranking.DEFAULT_PAGERANK_WEIGHT, "How much PageRank computed by the rank command affects scores, 0 disables it")
+
+	snippetLength    = flag.Int("snippet-length", snippet.DEFAULT_LENGTH, "Maximum length of each snippet fragment in bytes")
+	snippetFragments = flag.Int("snippet-fragments", snippet.DEFAULT_FRAGMENTS, "Maximum number of fragments in a snippet")
 )
 
 func main() {
-	listenAddr := fmt.Sprintf("%s:%d", *listenHost, *listenPort)
-	log.Printf("Starting server on %s...\n", listenAddr)
-	check(http.ListenAndServe(listenAddr, makeRouter()))
-}
-
-func makeRouter() *mux.Router {
-	r := mux.NewRouter().StrictSlash(true)
-
-	// Attach new handlers here:
-	r.HandleFunc("/", queryHandler)
-
-	return r
-}
-
-func queryHandler(w http.ResponseWriter, r *http.Request) {
-	keywords := strings.Split(r.URL.Query().Get("q"), ",")
-	if len(keywords) < 1 {
-		http.Error(w, "No query", http.StatusBadRequest)
-		return
+	flag.Parse()
+	bm25 := ranking.BM25{K1: *bm25K1, B: *bm25B, Boosts: ranking.DefaultBoosts()}
+	boosts, err := ranking.ParseBoosts(*boostsStr)
+	check(err)
+	for field, boost := range boosts {
+		bm25.Boosts[field] = boost
 	}
-
-	items := make([]index.IndexItem, 0)
-	for _, keyword := range keywords {
-		// Retrieving items for every keyword
-		items = append(items, index.Index.GetItems(strings.ToLower(keyword))...)
-	}
-
-	// Raking items by how often they appear
-	itemsRanked := make(map[index.IndexItem]int)
-	for _, item := range items {
-		itemsRanked[item]++
-	}
-
-	results := make(SearchResults, len(itemsRanked))
-	i := 0
-	for k, v := range itemsRanked {
-		results[i] = SearchResult{k, v}
-		i++
-	}
-	sort.Sort(sort.Reverse(results))
-
-	resultsOut := make([]SearchResultOutput, len(results))
-	i = 0
-	for _, item := range results {
-		resultsOut[i] = SearchResultOutput{
-			URL:  item.Item.URL.String(),
-			Rank: item.Rank,
+	ranker := ranking.PageRankBlend{Relevance: bm25, Weight: *pageRankWeight}
+	if *pageRankWeight != 0 {
+		ranker.PageRank, err = index.ReadPageRanks(*indexDir)
+		if os.IsNotExist(err) {
+			log.Println("PageRank is not computed yet, run the rank command to use it")
+		} else {
+			check(err)
 		}
-		i++
 	}
 
-	b, err := json.MarshalIndent(resultsOut, "", "  ")
-	if err != nil {
-		http.Error(w, "Internal error.", http.StatusInternalServerError)
-		log.Println(err)
-		return
-	}
+	idx, err := index.NewDiskIndex(*indexDir, "")
+	check(err)
+	defer idx.Close()
+	stats := idx.Stats()
+	log.Printf("Loaded index with %d documents and %d keywords\n", stats.Documents, stats.Keywords)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(b)
+	listenAddr := fmt.Sprintf("%s:%d", *listenHost, *listenPort)
+	log.Printf("Starting server on %s...\n", listenAddr)
+	check(http.ListenAndServe(listenAddr, makeRouter(&server{
+		index:   idx,
+		ranker:  ranker,
+		snippet: snippet.Options{Length: *snippetLength, Fragments: *snippetFragments, Analyzer: idx.Analyzer()},
+	})))
 }
 
-type SearchResultOutput struct {
-	URL  string `json:"url"`
-	Rank int    `json:"rank"`
+type server struct {
+	index   index.Index
+	ranker  ranking.PageRankBlend
+	snippet snippet.Options
 }
 
-type SearchResult struct {
-	Item index.IndexItem
-	Rank int
-}
+func makeRouter(s *server) *mux.Router {
+	r := mux.NewRouter().StrictSlash(true)
 
-type SearchResults []SearchResult
+	// Attach new handlers here:
+	r.HandleFunc("/", s.searchPageHandler)
+	r.HandleFunc("/api/v1/search", s.searchHandler)
+	r.HandleFunc("/api/v1/suggest", s.suggestHandler)
 
-func (p SearchResults) Len() int           { return len(p) }
-func (p SearchResults) Less(i, j int) bool { return p[i].Rank < p[j].Rank }
-func (p SearchResults) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+	return r
+}
 
 func check(err error) {
 	if err != nil {
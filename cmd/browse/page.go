@@ -0,0 +1,149 @@
+package main
+
+import (
+	"embed"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	// Snippets are already escaped, apart from the highlighting
+	"snippet": func(s string) template.HTML { return template.HTML(s) },
+	// Positions of the first and the last result on a page, starting from 1
+	"first": func(p searchPage) int { return p.Offset + 1 },
+	"last":  func(p searchPage) int { return p.Offset + len(p.Results) },
+}).ParseFS(templateFiles, "templates/*.html"))
+
+type searchPage struct {
+	SearchOutput
+	Error string
+	// Links to the previous and the next page of results, if there are any
+	Previous, Next string
+	// Link to results of the corrected query
+	Correction string
+	Facets     []facetGroup
+	// Filters that are kept when the query is changed
+	Filters map[string]string
+}
+
+// facetGroup is a list of links that filter results by values of an
+// attribute.
+type facetGroup struct {
+	Name  string
+	Links []facetLink
+	// Link that removes the filter, if it's set
+	Clear string
+}
+
+type facetLink struct {
+	Label  string
+	Count  int
+	URL    string
+	Active bool
+}
+
+// searchPageHandler serves the search page. Results are shown when the "q"
+// parameter is set.
+func (s *server) searchPageHandler(w http.ResponseWriter, r *http.Request) {
+	page := searchPage{Filters: make(map[string]string)}
+	for _, name := range []string{FILTER_HOST, FILTER_LANG, FILTER_TYPE, FILTER_FROM, FILTER_TO} {
+		if value := r.URL.Query().Get(name); value != "" {
+			page.Filters[name] = value
+		}
+	}
+	status := http.StatusOK
+	if r.URL.Query().Get("q") != "" {
+		output, err := s.search(r)
+		page.SearchOutput = output
+		if err != nil {
+			if _, ok := err.(requestError); ok {
+				status = http.StatusBadRequest
+				page.Error = err.Error()
+			} else {
+				handleError(w, err)
+				return
+			}
+		}
+		values := r.URL.Query()
+		if page.Offset > 0 {
+			previous := page.Offset - page.Limit
+			if previous < 0 {
+				previous = 0
+			}
+			page.Previous = link(values, "offset", strconv.Itoa(previous))
+		}
+		if page.DidYouMean != "" {
+			page.Correction = link(values, "q", page.DidYouMean, "offset", "")
+		}
+		if page.Offset+page.Limit < page.Total {
+			page.Next = link(values, "offset", strconv.Itoa(page.Offset+page.Limit))
+		}
+		page.Facets = facetGroups(values, page.SearchOutput.Facets)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := templates.ExecuteTemplate(w, "search.html", page); err != nil {
+		log.Println(err)
+	}
+}
+
+func facetGroups(values url.Values, facets FacetsOutput) []facetGroup {
+	groups := []facetGroup{
+		countsGroup(values, "Hosts", FILTER_HOST, facets.Hosts),
+		countsGroup(values, "Languages", FILTER_LANG, facets.Languages),
+		countsGroup(values, "Content types", FILTER_TYPE, facets.ContentTypes),
+	}
+	dates := facetGroup{Name: "Fetched"}
+	if values.Get(FILTER_FROM) != "" {
+		dates.Clear = link(values, FILTER_FROM, "", "offset", "")
+	}
+	for _, d := range facets.Dates {
+		dates.Links = append(dates.Links, facetLink{
+			Label: "Past " + d.Bucket,
+			Count: d.Count,
+			URL:   link(values, FILTER_FROM, d.Since.UTC().Format(time.RFC3339), "offset", ""),
+		})
+	}
+	return append(groups, dates)
+}
+
+func countsGroup(values url.Values, name, param string, counts []FacetCountOutput) facetGroup {
+	group := facetGroup{Name: name}
+	if values.Get(param) != "" {
+		group.Clear = link(values, param, "", "offset", "")
+	}
+	for _, c := range counts {
+		group.Links = append(group.Links, facetLink{
+			Label:  c.Value,
+			Count:  c.Count,
+			URL:    link(values, param, c.Value, "offset", ""),
+			Active: values.Get(param) == c.Value,
+		})
+	}
+	return group
+}
+
+// link returns a link to the search page with parameters of the current page
+// changed to pairs of names and values. Empty values remove parameters.
+func link(values url.Values, changes ...string) string {
+	changed := make(url.Values, len(values))
+	for name, v := range values {
+		changed[name] = v
+	}
+	for n := 0; n+1 < len(changes); n += 2 {
+		if changes[n+1] == "" {
+			changed.Del(changes[n])
+		} else {
+			changed.Set(changes[n], changes[n+1])
+		}
+	}
+	return "/?" + changed.Encode()
+}
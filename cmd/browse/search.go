@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.roman.zone/crawl/index"
+	"go.roman.zone/crawl/index/ranking"
+	"go.roman.zone/crawl/index/snippet"
+	"go.roman.zone/crawl/index/suggest"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DEFAULT_LIMIT = 10
+	// Hard limit on the number of results in one response
+	MAX_LIMIT = 100
+)
+
+type SearchResultOutput struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	// HTML with matched keywords highlighted
+	Snippet     string    `json:"snippet,omitempty"`
+	Language    string    `json:"language,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	FetchTime   time.Time `json:"fetch_time"`
+	Score       float64   `json:"score"`
+}
+
+type SearchOutput struct {
+	Query  string `json:"query"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	// Number of all documents that match the query
+	Total   int                  `json:"total"`
+	Results []SearchResultOutput `json:"results"`
+	// Counts of all matching documents by their attributes
+	Facets FacetsOutput `json:"facets"`
+	// Corrected query if nothing was found
+	DidYouMean string `json:"did_you_mean,omitempty"`
+	// Time it took to run the query
+	Took time.Duration `json:"took_ns"`
+}
+
+// requestError is an error caused by a bad request rather than by the server.
+type requestError struct {
+	err error
+}
+
+func (e requestError) Error() string {
+	return e.err.Error()
+}
+
+// search runs a query from the "q" parameter of a request and returns a page
+// of results selected by the "offset" and "limit" parameters. The limit needs
+// to be positive and can't be larger than MAX_LIMIT. Results can be filtered
+// with parameters described by parseFilters.
+func (s *server) search(r *http.Request) (SearchOutput, error) {
+	start := time.Now()
+	output := SearchOutput{Query: r.URL.Query().Get("q"), Limit: DEFAULT_LIMIT}
+	var err error
+	if output.Offset, err = intParameter(r, "offset", 0); err != nil {
+		return output, err
+	}
+	if output.Limit, err = intParameter(r, "limit", DEFAULT_LIMIT); err != nil {
+		return output, err
+	}
+	if output.Limit == 0 {
+		return output, requestError{err: errors.New("limit needs to be at least 1")}
+	}
+	if output.Limit > MAX_LIMIT {
+		output.Limit = MAX_LIMIT
+	}
+	query, err := index.ParseQuery(output.Query, s.index.Analyzer())
+	if err != nil {
+		return output, requestError{err: err}
+	}
+	filters, err := parseFilters(r)
+	if err != nil {
+		return output, err
+	}
+	if len(filters) > 0 {
+		query = append(index.And{query}, filters...)
+	}
+
+	hits, err := s.index.Search(query)
+	if err != nil {
+		return output, err
+	}
+	output.Total = len(hits)
+	output.Facets = facetsOutput(hits)
+	if output.Total == 0 {
+		if output.DidYouMean, err = suggest.DidYouMean(s.index, output.Query); err != nil {
+			return output, err
+		}
+	}
+	results := []ranking.Result{}
+	if output.Offset < len(hits) {
+		results, err = s.ranker.RankTop(hits, s.index, output.Offset+output.Limit)
+		if err != nil {
+			return output, err
+		}
+		results = results[output.Offset:]
+	}
+
+	output.Results = make([]SearchResultOutput, 0, len(results))
+	for _, result := range results {
+		text, err := s.index.Text(result.Document.ID)
+		if err != nil {
+			return output, err
+		}
+		output.Results = append(output.Results, SearchResultOutput{
+			URL:         result.Document.URL.String(),
+			Title:       result.Document.Title,
+			Snippet:     snippet.Make(text, result.Keywords, s.snippet),
+			Language:    result.Document.Language,
+			ContentType: result.Document.ContentType,
+			FetchTime:   result.Document.FetchTime,
+			Score:       result.Score,
+		})
+	}
+	output.Took = time.Since(start)
+	return output, nil
+}
+
+// intParameter parses a non-negative integer parameter of a request.
+func intParameter(r *http.Request, name string, defaultValue int) (int, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, requestError{err: fmt.Errorf("invalid %s %q", name, s)}
+	}
+	return n, nil
+}
+
+// searchHandler serves search results as JSON.
+func (s *server) searchHandler(w http.ResponseWriter, r *http.Request) {
+	output, err := s.search(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	b, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+func handleError(w http.ResponseWriter, err error) {
+	if _, ok := err.(requestError); ok {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "Internal error.", http.StatusInternalServerError)
+	log.Println(err)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"go.roman.zone/crawl/index/suggest"
+	"net/http"
+)
+
+type SuggestionOutput struct {
+	Text      string `json:"text"`
+	Documents int    `json:"documents"`
+}
+
+// suggestHandler serves completions of the "prefix" parameter as JSON.
+func (s *server) suggestHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := intParameter(r, "limit", suggest.DEFAULT_LIMIT)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	if limit > MAX_LIMIT {
+		limit = MAX_LIMIT
+	}
+	suggestions, err := suggest.Complete(s.index, r.URL.Query().Get("prefix"), limit)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	output := make([]SuggestionOutput, len(suggestions))
+	for i, suggestion := range suggestions {
+		output[i] = SuggestionOutput{Text: suggestion.Text, Documents: suggestion.Documents}
+	}
+	b, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"go.roman.zone/crawl/crawler"
+	"go.roman.zone/crawl/crawler/classifier"
+	"go.roman.zone/crawl/index"
 	"log"
 	"net/http"
 	_ "net/http/pprof"
@@ -13,11 +15,31 @@ import (
 
 var (
 	seedURL     = flag.String("seed", "https://example.com", "URL of the page to use as a seed")
-	keywordsStr = flag.String("keywords", "", "Comma-separated list of keywords that define a topic")
+	indexDir    = flag.String("index", index.STORAGE_DIR, "Directory to store the index in")
+	analyzer    = flag.String("analyzer", "", "Analyzer of a new index: default or english (with stopword removal and stemming); existing indexes keep theirs")
 	targetCount = flag.Int("index-target", 1000, "Number of unique pages to index")
 	timeLimit   = flag.Duration("time-limit", 0, "Maximum time the crawler should run for")
+
+	languagesStr   = flag.String("languages", "", "Comma-separated list of language codes, e.g. \"en,de\"; pages in other languages are filtered according to -language-filter")
+	languageFilter = flag.String("language-filter", "both", "What to do with pages in other languages: skip indexing them (index), don't follow their links (expand) or both")
+
+	classifierName = flag.String("classifier", "all", "Topic classifier to use: all, any, expr, weighted, regex, bayes or similarity")
+	keywordsStr    = flag.String("keywords", "", "Comma-separated list of keywords that define a topic, phrases can be put in quotes (all, any)")
+	expression     = flag.String("expr", "", "Boolean expression over keywords, e.g. \"go AND (goroutine OR channel)\" (expr)")
+	weightsStr     = flag.String("weights", "", "Comma-separated list of keywords with weights, e.g. \"golang:3,go:1\" (weighted)")
+	threshold      = flag.Float64("threshold", 1, "Minimum sum of weights for a page to be topical (weighted)")
+	modelFile      = flag.String("model", "model.gob", "Naive Bayes model created with the train command (bayes)")
+	references     = flag.String("references", "", "Directory with reference pages or a file with their URLs (similarity)")
+	background     = flag.String("background", "", "Directory with unrelated pages or a file with their URLs (similarity)")
+	minSimilarity  = flag.Float64("min-similarity", 0.2, "Minimum cosine similarity for a page to be topical (similarity)")
+	stem           = flag.Bool("stem", false, "Match keywords using English stemming, e.g. \"crawling\" matches \"crawled\"")
+	patterns       stringList
 )
 
+func init() {
+	flag.Var(&patterns, "regex", "Regular expression that topical pages must match, can be repeated (regex)")
+}
+
 func main() {
 	go func() {
 		log.Println(http.ListenAndServe("localhost:6060", nil))
@@ -26,14 +48,118 @@ func main() {
 	flag.Parse()
 	seedURLParsed, err := url.Parse(*seedURL)
 	check(err)
-	keywords := strings.Split(*keywordsStr, ",")
+	topic, err := makeClassifier()
+	check(err)
+	languages, err := makeLanguageFilter()
+	check(err)
+	idx, err := index.NewDiskIndex(*indexDir, *analyzer)
+	check(err)
+	links, err := index.OpenLinkGraph(*indexDir)
+	check(err)
 
-	fmt.Printf("Crawling using %s as a seed with keywords: %s.\n",
-		seedURLParsed.String(), strings.Join(keywords, ","))
-	urls := crawler.Crawl(*seedURLParsed, keywords, *targetCount, *timeLimit)
+	fmt.Printf("Crawling using %s as a seed with %s classifier.\n",
+		seedURLParsed.String(), *classifierName)
+	urls := crawler.Crawl(crawler.Config{
+		Seed:        *seedURLParsed,
+		Topic:       topic,
+		Languages:   languages,
+		Index:       idx,
+		Links:       links,
+		TargetCount: *targetCount,
+		TimeLimit:   *timeLimit,
+	})
+	check(idx.Close())
+	check(links.Close())
 	fmt.Printf("Indexed %d pages.\n", len(urls))
 }
 
+func makeClassifier() (classifier.Classifier, error) {
+	options := classifier.MatchOptions{Stem: *stem}
+	switch *classifierName {
+	case "all":
+		return classifier.AllKeywords{Keywords: splitKeywords(*keywordsStr), MatchOptions: options}, nil
+	case "any":
+		return classifier.AnyKeywords{Keywords: splitKeywords(*keywordsStr), MatchOptions: options}, nil
+	case "expr":
+		return classifier.ParseExpression(*expression, options)
+	case "weighted":
+		weights, err := classifier.ParseWeights(*weightsStr)
+		if err != nil {
+			return nil, err
+		}
+		return classifier.Weighted{Weights: weights, Threshold: *threshold, MatchOptions: options}, nil
+	case "regex":
+		return classifier.NewRegexp(patterns)
+	case "bayes":
+		return classifier.LoadNaiveBayes(*modelFile)
+	case "similarity":
+		referencePages, err := crawler.LoadPages(*references)
+		if err != nil {
+			return nil, err
+		}
+		backgroundPages := make([]string, 0)
+		if *background != "" {
+			if backgroundPages, err = crawler.LoadPages(*background); err != nil {
+				return nil, err
+			}
+		}
+		return classifier.NewSimilarity(referencePages, backgroundPages, *minSimilarity), nil
+	default:
+		return nil, fmt.Errorf("unknown classifier %q", *classifierName)
+	}
+}
+
+func makeLanguageFilter() (crawler.LanguageFilter, error) {
+	filter := crawler.LanguageFilter{Languages: splitKeywords(*languagesStr)}
+	switch *languageFilter {
+	case "index":
+		filter.Index = true
+	case "expand":
+		filter.Expand = true
+	case "both":
+		filter.Index, filter.Expand = true, true
+	default:
+		return filter, fmt.Errorf("unknown language filter %q", *languageFilter)
+	}
+	return filter, nil
+}
+
+// splitKeywords splits a comma-separated list. Commas inside double quotes
+// don't split, and the quotes are kept so that phrases stay phrases.
+func splitKeywords(s string) []string {
+	keywords := make([]string, 0)
+	add := func(keyword string) {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	quoted := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			add(s[start:i])
+			start = i + 1
+		}
+	}
+	add(s[start:])
+	return keywords
+}
+
+// stringList is a flag that can be specified multiple times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func check(err error) {
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitKeywords(t *testing.T) {
+	cases := map[string][]string{
+		"":                   {},
+		"go, rust,,":         {"go", "rust"},
+		`"a, b",c`:           {`"a, b"`, "c"},
+		`"web crawler", go`:  {`"web crawler"`, "go"},
+		`"unterminated, end`: {`"unterminated, end`},
+	}
+	for s, expected := range cases {
+		if keywords := splitKeywords(s); !reflect.DeepEqual(keywords, expected) {
+			t.Errorf("Expected %q for %q, got %q", expected, s, keywords)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Command rank computes PageRank of pages from links recorded by the crawler
+// and saves it into the index directory, where the browse command reads it
+// from.
+package main
+
+import (
+	"flag"
+	"go.roman.zone/crawl/index"
+	"go.roman.zone/crawl/index/ranking"
+	"log"
+	"sort"
+)
+
+var (
+	indexDir   = flag.String("index", index.STORAGE_DIR, "Directory with the index and the links")
+	damping    = flag.Float64("damping", ranking.DEFAULT_DAMPING, "Probability of following a link rather than jumping to a random page")
+	iterations = flag.Int("iterations", ranking.DEFAULT_ITERATIONS, "Maximum number of iterations")
+	tolerance  = flag.Float64("tolerance", ranking.DEFAULT_TOLERANCE, "Stop once scores change by less than this in total")
+	top        = flag.Int("top", 10, "Number of pages with the highest PageRank to print")
+)
+
+func main() {
+	flag.Parse()
+	links, err := index.ReadLinks(*indexDir)
+	check(err)
+	log.Printf("Computing PageRank of %d crawled pages...\n", len(links))
+	scores := ranking.PageRank(links, *damping, *iterations, *tolerance)
+	check(index.WritePageRanks(*indexDir, scores))
+	log.Printf("Saved PageRank of %d pages\n", len(scores))
+
+	pages := make([]string, 0, len(scores))
+	for page := range scores {
+		pages = append(pages, page)
+	}
+	sort.Slice(pages, func(i, j int) bool { return scores[pages[i]] > scores[pages[j]] })
+	if len(pages) > *top {
+		pages = pages[:*top]
+	}
+	for _, page := range pages {
+		log.Printf("%8.3f %s\n", scores[page], page)
+	}
+}
+
+func check(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
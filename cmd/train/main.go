@@ -0,0 +1,94 @@
+// Command train trains a Naive Bayes topic classifier on labelled example pages
+// and evaluates it.
+//
+// Examples can be read from directories with saved pages (one page per file)
+// or from files with lists of URLs (one URL per line) that are fetched first.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go.roman.zone/crawl/crawler"
+	"go.roman.zone/crawl/crawler/classifier"
+	"log"
+	"math/rand"
+)
+
+var (
+	modelFile   = flag.String("model", "model.gob", "File to save the model to (or load it from when evaluating)")
+	topicalDir  = flag.String("topical-dir", "", "Directory with pages that match the topic")
+	otherDir    = flag.String("other-dir", "", "Directory with pages that don't match the topic")
+	topicalURLs = flag.String("topical-urls", "", "File with URLs of pages that match the topic")
+	otherURLs   = flag.String("other-urls", "", "File with URLs of pages that don't match the topic")
+	evaluate    = flag.Bool("evaluate", false, "Evaluate an existing model instead of training a new one")
+	holdout     = flag.Float64("holdout", 0.2, "Fraction of examples to keep for evaluation when training")
+	threshold   = flag.Float64("threshold", 0.5, "Minimum probability for a page to be topical")
+	stem        = flag.Bool("stem", false, "Use English stemming")
+)
+
+func main() {
+	flag.Parse()
+
+	examples := make([]classifier.Example, 0)
+	for _, source := range []struct {
+		path    string
+		topical bool
+	}{
+		{*topicalDir, true},
+		{*topicalURLs, true},
+		{*otherDir, false},
+		{*otherURLs, false},
+	} {
+		if source.path == "" {
+			continue
+		}
+		loaded, err := load(source.path, source.topical)
+		check(err)
+		examples = append(examples, loaded...)
+	}
+	if len(examples) == 0 {
+		log.Fatal("No examples. Specify at least one directory or a URL list.")
+	}
+
+	if *evaluate {
+		model, err := classifier.LoadNaiveBayes(*modelFile)
+		check(err)
+		fmt.Printf("Evaluated on %d examples: %s\n", len(examples), classifier.Evaluate(model, examples))
+		return
+	}
+
+	rand.Shuffle(len(examples), func(i, j int) {
+		examples[i], examples[j] = examples[j], examples[i]
+	})
+	testCount := int(float64(len(examples)) * *holdout)
+	test, train := examples[:testCount], examples[testCount:]
+
+	model := classifier.NewNaiveBayes(classifier.MatchOptions{Stem: *stem})
+	model.Threshold = *threshold
+	for _, example := range train {
+		model.Train(example.Content, example.Topical)
+	}
+	check(model.Save(*modelFile))
+	fmt.Printf("Trained on %d examples, saved the model to %s.\n", len(train), *modelFile)
+	if len(test) > 0 {
+		fmt.Printf("Evaluated on %d examples: %s\n", len(test), classifier.Evaluate(model, test))
+	}
+}
+
+func load(path string, topical bool) ([]classifier.Example, error) {
+	pages, err := crawler.LoadPages(path)
+	if err != nil {
+		return nil, err
+	}
+	examples := make([]classifier.Example, len(pages))
+	for i, content := range pages {
+		examples[i] = classifier.Example{Content: content, Topical: topical}
+	}
+	return examples, nil
+}
+
+func check(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
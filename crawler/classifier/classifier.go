@@ -1,24 +1,73 @@
 package classifier
 
-import (
-	"strings"
-)
-
-// IsTopical function determines if a page matches a topic or not. Topic is
-// represented by a set of keywords.
+// Classifier determines if a page matches a topic or not.
 //
 // It is recommended to clean up the page before processing. For example, if
 // the page is in HTML format (which they would be in most cases), then it
 // would be a good idea to remove all the tags and do some other kind of
 // processing, if necessary.
+type Classifier interface {
+	Classify(pageContent string) Result
+}
+
+// Result of a page classification.
+type Result struct {
+	// Score shows how well the page matches the topic. Its range depends on
+	// the classifier.
+	Score   float64
+	Topical bool
+}
+
+// IsTopical function determines if a page matches a topic or not. Topic is
+// represented by a set of keywords, all of which need to be present on the
+// page.
 func IsTopical(pageContent string, keywords []string) bool {
-	pageContent = strings.ToUpper(pageContent)
-	// Also converting both page content strings and keywords to
-	// make the search case insensitive.
+	return AllKeywords{Keywords: keywords}.Classify(pageContent).Topical
+}
+
+// AllKeywords classifier requires every keyword to be present on the page.
+// Score is a fraction of keywords that have been found.
+type AllKeywords struct {
+	Keywords []string
+	MatchOptions
+}
+
+func (c AllKeywords) Classify(pageContent string) Result {
+	found := countFound(newPage(pageContent, c.MatchOptions), c.Keywords)
+	return Result{
+		Score:   fraction(found, len(c.Keywords)),
+		Topical: found == len(c.Keywords),
+	}
+}
+
+// AnyKeywords classifier requires at least one of the keywords to be present
+// on the page. Score is a fraction of keywords that have been found.
+type AnyKeywords struct {
+	Keywords []string
+	MatchOptions
+}
+
+func (c AnyKeywords) Classify(pageContent string) Result {
+	found := countFound(newPage(pageContent, c.MatchOptions), c.Keywords)
+	return Result{
+		Score:   fraction(found, len(c.Keywords)),
+		Topical: found > 0 || len(c.Keywords) == 0,
+	}
+}
+
+func countFound(p page, keywords []string) int {
+	found := 0
 	for _, keyword := range keywords {
-		if !strings.Contains(pageContent, strings.ToUpper(keyword)) {
-			return false
+		if p.contains(keyword) {
+			found++
 		}
 	}
-	return true
+	return found
+}
+
+func fraction(n, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+	return float64(n) / float64(total)
 }
@@ -0,0 +1,91 @@
+package classifier
+
+import "testing"
+
+func TestExpression(t *testing.T) {
+	c, err := ParseExpression(`golang AND (concurrency OR goroutine) NOT java`, MatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]bool{
+		"Golang has a goroutine":      true,
+		"golang concurrency":          true,
+		"golang and java concurrency": false,
+		"golang":                      false,
+		"concurrency goroutine":       false,
+	}
+	for page, expected := range cases {
+		if c.Classify(page).Topical != expected {
+			t.Errorf("Expected %v for %q", expected, page)
+		}
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	weights, err := ParseWeights("golang:3,go:1,java:-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Weighted{Weights: weights, Threshold: 2}
+	if r := c.Classify("Golang: go and Java"); r.Score != 2 || !r.Topical {
+		t.Errorf("Unexpected result %+v", r)
+	}
+	if r := c.Classify("Java"); r.Topical {
+		t.Errorf("Unexpected result %+v", r)
+	}
+}
+
+func TestKeywordMatching(t *testing.T) {
+	cases := []struct {
+		keyword  string
+		page     string
+		options  MatchOptions
+		expected bool
+	}{
+		{"go", "Go is good", MatchOptions{}, true},
+		{"go", "Google is good", MatchOptions{}, false},
+		{`"machine learning"`, "Machine learning is fun", MatchOptions{}, true},
+		{`"machine learning"`, "Learning about machine", MatchOptions{}, false},
+		{"machine learning", "Learning about machine", MatchOptions{}, true},
+		{"crawling", "The page was crawled", MatchOptions{}, false},
+		{"crawling", "The page was crawled", MatchOptions{Stem: true}, true},
+		{"ΣΟΦΟΣ", "σοφος", MatchOptions{}, true},
+		{"C++", "Written in C++ and Go", MatchOptions{}, true},
+		{"C++", "Written in C and Go", MatchOptions{}, false},
+	}
+	for _, c := range cases {
+		if newPage(c.page, c.options).contains(c.keyword) != c.expected {
+			t.Errorf("Expected %v for keyword %q on %q", c.expected, c.keyword, c.page)
+		}
+	}
+}
+
+func TestNaiveBayes(t *testing.T) {
+	c := NewNaiveBayes(MatchOptions{})
+	c.Train("goroutines and channels make concurrency in go simple", true)
+	c.Train("the go compiler produces static binaries", true)
+	c.Train("recipe for an apple pie with cinnamon", false)
+	c.Train("baking bread at home with simple tools", false)
+	e := Evaluate(c, []Example{
+		{"concurrency with goroutines", true},
+		{"cinnamon apple bread", false},
+	})
+	if e.Precision() != 1 || e.Recall() != 1 {
+		t.Errorf("Unexpected evaluation: %s", e)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	c := NewSimilarity([]string{
+		"goroutines and channels make concurrency in go simple",
+		"go has goroutines, channels and a fast compiler",
+	}, []string{
+		"recipe for an apple pie with cinnamon",
+	}, 0.2)
+	if r := c.Classify("channels and goroutines"); !r.Topical {
+		t.Errorf("Expected a topical result, got %+v", r)
+	}
+	if r := c.Classify("apple pie recipe"); r.Topical {
+		t.Errorf("Expected a non-topical result, got %+v", r)
+	}
+}
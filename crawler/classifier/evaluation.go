@@ -0,0 +1,77 @@
+package classifier
+
+import (
+	"fmt"
+)
+
+// Example is a labelled page that can be used for training and evaluation.
+type Example struct {
+	Content string
+	Topical bool
+}
+
+// Evaluation holds results of running a classifier on labelled examples.
+type Evaluation struct {
+	TruePositives  int
+	FalsePositives int
+	TrueNegatives  int
+	FalseNegatives int
+}
+
+// Evaluate classifies every example and compares the results with labels.
+func Evaluate(c Classifier, examples []Example) Evaluation {
+	var e Evaluation
+	for _, example := range examples {
+		topical := c.Classify(example.Content).Topical
+		switch {
+		case topical && example.Topical:
+			e.TruePositives++
+		case topical && !example.Topical:
+			e.FalsePositives++
+		case !topical && example.Topical:
+			e.FalseNegatives++
+		default:
+			e.TrueNegatives++
+		}
+	}
+	return e
+}
+
+// Precision is a fraction of pages classified as topical that are actually
+// topical.
+func (e Evaluation) Precision() float64 {
+	return ratio(e.TruePositives, e.TruePositives+e.FalsePositives)
+}
+
+// Recall is a fraction of topical pages that have been classified as topical.
+func (e Evaluation) Recall() float64 {
+	return ratio(e.TruePositives, e.TruePositives+e.FalseNegatives)
+}
+
+// F1 is a harmonic mean of precision and recall.
+func (e Evaluation) F1() float64 {
+	p, r := e.Precision(), e.Recall()
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+func (e Evaluation) Accuracy() float64 {
+	return ratio(e.TruePositives+e.TrueNegatives,
+		e.TruePositives+e.TrueNegatives+e.FalsePositives+e.FalseNegatives)
+}
+
+func (e Evaluation) String() string {
+	return fmt.Sprintf("precision %.3f, recall %.3f, F1 %.3f, accuracy %.3f "+
+		"(TP %d, FP %d, TN %d, FN %d)",
+		e.Precision(), e.Recall(), e.F1(), e.Accuracy(),
+		e.TruePositives, e.FalsePositives, e.TrueNegatives, e.FalseNegatives)
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
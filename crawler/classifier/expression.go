@@ -0,0 +1,234 @@
+package classifier
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+var (
+	ErrEmptyExpression = errors.New("expression is empty")
+)
+
+// Expression classifier evaluates a boolean expression over keywords. For
+// example:
+//
+//	golang AND (concurrency OR goroutine) AND NOT java
+//
+// Operators are AND, OR and NOT (in the order of decreasing precedence: NOT,
+// AND, OR). Keywords that follow each other without an operator are joined
+// with AND. Phrases and keywords that look like operators can be put in double
+// quotes. Score is 1 if the expression matches and 0 otherwise.
+type Expression struct {
+	root    exprNode
+	options MatchOptions
+}
+
+// ParseExpression parses a boolean expression for the Expression classifier.
+func ParseExpression(expr string, options MatchOptions) (*Expression, error) {
+	tokens, err := lexExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, ErrEmptyExpression
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in expression", p.tokens[p.pos].value)
+	}
+	return &Expression{root: root, options: options}, nil
+}
+
+func (c *Expression) Classify(pageContent string) Result {
+	if c.root.eval(newPage(pageContent, c.options)) {
+		return Result{Score: 1, Topical: true}
+	}
+	return Result{Score: 0, Topical: false}
+}
+
+type exprNode interface {
+	eval(p page) bool
+}
+
+type keywordNode string
+
+func (n keywordNode) eval(p page) bool { return p.contains(string(n)) }
+
+type notNode struct{ operand exprNode }
+
+func (n notNode) eval(p page) bool { return !n.operand.eval(p) }
+
+type andNode []exprNode
+
+func (n andNode) eval(p page) bool {
+	for _, operand := range n {
+		if !operand.eval(p) {
+			return false
+		}
+	}
+	return true
+}
+
+type orNode []exprNode
+
+func (n orNode) eval(p page) bool {
+	for _, operand := range n {
+		if operand.eval(p) {
+			return true
+		}
+	}
+	return false
+}
+
+type exprTokenType int
+
+const (
+	exprKeyword exprTokenType = iota
+	exprAnd
+	exprOr
+	exprNot
+	exprOpen
+	exprClose
+)
+
+type exprToken struct {
+	kind  exprTokenType
+	value string
+}
+
+func lexExpression(expr string) ([]exprToken, error) {
+	tokens := make([]exprToken, 0)
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, exprToken{exprOpen, "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, exprToken{exprClose, ")"})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.New("unterminated quote in expression")
+			}
+			tokens = append(tokens, exprToken{exprKeyword, string(runes[i : end+1])})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) &&
+				runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+			word := string(runes[i:end])
+			switch word {
+			case "AND":
+				tokens = append(tokens, exprToken{exprAnd, word})
+			case "OR":
+				tokens = append(tokens, exprToken{exprOr, word})
+			case "NOT":
+				tokens = append(tokens, exprToken{exprNot, word})
+			default:
+				tokens = append(tokens, exprToken{exprKeyword, word})
+			}
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek() (exprToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return exprToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	operands := make(orNode, 0)
+	for {
+		operand, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if t, ok := p.peek(); !ok || t.kind != exprOr {
+			break
+		}
+		p.pos++
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	operands := make(andNode, 0)
+	for {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		t, ok := p.peek()
+		if !ok || t.kind == exprOr || t.kind == exprClose {
+			break
+		}
+		if t.kind == exprAnd {
+			p.pos++
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, errors.New("unexpected end of expression")
+	}
+	switch t.kind {
+	case exprNot:
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	case exprOpen:
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.peek(); !ok || t.kind != exprClose {
+			return nil, errors.New("missing closing parenthesis in expression")
+		}
+		p.pos++
+		return node, nil
+	case exprKeyword:
+		p.pos++
+		return keywordNode(t.value), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q in expression", t.value)
+	}
+}
@@ -0,0 +1,110 @@
+package classifier
+
+import (
+	"encoding/gob"
+	"math"
+	"os"
+)
+
+const (
+	classOther   = 0
+	classTopical = 1
+)
+
+// NaiveBayes is a multinomial Naive Bayes classifier that learns the topic from
+// labelled example pages. Score is the probability of a page being topical.
+//
+// The model needs to be trained before use. Training isn't safe to do
+// concurrently with classification.
+type NaiveBayes struct {
+	// Minimum probability for a page to be considered topical
+	Threshold float64
+	Options   MatchOptions
+
+	// Number of training pages in each class
+	PageCount [2]int
+	// Number of occurrences of each term in each class
+	TermCount [2]map[string]int
+	// Total number of terms in each class
+	TotalTerms [2]int
+	// Number of distinct terms seen during training
+	VocabularySize int
+}
+
+func NewNaiveBayes(options MatchOptions) *NaiveBayes {
+	return &NaiveBayes{
+		Threshold: 0.5,
+		Options:   options,
+		TermCount: [2]map[string]int{make(map[string]int), make(map[string]int)},
+	}
+}
+
+// LoadNaiveBayes reads a model previously written with Save.
+func LoadNaiveBayes(filename string) (*NaiveBayes, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c := new(NaiveBayes)
+	if err := gob.NewDecoder(f).Decode(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save writes the model into a file.
+func (c *NaiveBayes) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(c); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Train adds an example page to the model.
+func (c *NaiveBayes) Train(pageContent string, topical bool) {
+	class := classOther
+	if topical {
+		class = classTopical
+	}
+	c.PageCount[class]++
+	for _, t := range c.Options.terms(pageContent) {
+		if c.TermCount[classOther][t] == 0 && c.TermCount[classTopical][t] == 0 {
+			c.VocabularySize++
+		}
+		c.TermCount[class][t]++
+		c.TotalTerms[class]++
+	}
+}
+
+func (c *NaiveBayes) Classify(pageContent string) Result {
+	totalPages := c.PageCount[classOther] + c.PageCount[classTopical]
+	if totalPages == 0 {
+		return Result{Score: 0, Topical: false}
+	}
+	var logProb [2]float64
+	for class := range logProb {
+		// Adding one to avoid zero probabilities for classes without examples
+		logProb[class] = math.Log(float64(c.PageCount[class]+1) / float64(totalPages+2))
+	}
+	for _, t := range c.Options.terms(pageContent) {
+		if c.TermCount[classOther][t] == 0 && c.TermCount[classTopical][t] == 0 {
+			continue // Unknown terms don't tell anything about the topic
+		}
+		for class := range logProb {
+			// Laplace smoothing
+			logProb[class] += math.Log(float64(c.TermCount[class][t]+1) /
+				float64(c.TotalTerms[class]+c.VocabularySize))
+		}
+	}
+	probability := 1 / (1 + math.Exp(logProb[classOther]-logProb[classTopical]))
+	return Result{
+		Score:   probability,
+		Topical: probability >= c.Threshold,
+	}
+}
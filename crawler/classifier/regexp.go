@@ -0,0 +1,37 @@
+package classifier
+
+import (
+	"regexp"
+)
+
+// Regexp classifier requires every regular expression to match the page.
+// Score is a fraction of expressions that matched.
+//
+// Matching is case sensitive unless expressions start with the (?i) flag.
+type Regexp []*regexp.Regexp
+
+// NewRegexp compiles all the patterns into a Regexp classifier.
+func NewRegexp(patterns []string) (Regexp, error) {
+	c := make(Regexp, len(patterns))
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = re
+	}
+	return c, nil
+}
+
+func (c Regexp) Classify(pageContent string) Result {
+	matched := 0
+	for _, re := range c {
+		if re.MatchString(pageContent) {
+			matched++
+		}
+	}
+	return Result{
+		Score:   fraction(matched, len(c)),
+		Topical: matched == len(c),
+	}
+}
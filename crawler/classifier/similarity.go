@@ -0,0 +1,107 @@
+package classifier
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"math"
+)
+
+// Similarity classifier compares pages with a set of reference pages that
+// define the topic. References are combined into a single TF-IDF vector (a
+// centroid) and the page is topical if cosine similarity between its vector
+// and the centroid reaches the threshold. Score is the similarity, from 0 to 1.
+//
+// Pages are split into terms with the default analyzer, the same way they are
+// when added into an index that uses it.
+type Similarity struct {
+	Threshold float64
+	idf       map[string]float64
+	// IDF of terms that didn't appear in any of the pages used for training
+	unknownIDF float64
+	centroid   map[string]float64
+}
+
+// NewSimilarity creates a Similarity classifier from reference pages. Optional
+// background pages aren't a part of the topic, but they help determine which
+// terms are common and shouldn't matter as much.
+func NewSimilarity(references, background []string, threshold float64) *Similarity {
+	docFreq := make(map[string]int)
+	referenceTerms := make([]map[string]int, len(references))
+	for i, content := range references {
+		referenceTerms[i] = termFrequencies(content)
+		for t := range referenceTerms[i] {
+			docFreq[t]++
+		}
+	}
+	for _, content := range background {
+		for t := range termFrequencies(content) {
+			docFreq[t]++
+		}
+	}
+
+	// Smoothed IDF, so that terms that appear in every page still count.
+	docCount := float64(len(references) + len(background))
+	c := &Similarity{
+		Threshold:  threshold,
+		idf:        make(map[string]float64, len(docFreq)),
+		unknownIDF: math.Log(docCount+1) + 1,
+		centroid:   make(map[string]float64),
+	}
+	for t, df := range docFreq {
+		c.idf[t] = math.Log((docCount+1)/float64(df+1)) + 1
+	}
+	for _, terms := range referenceTerms {
+		for t, w := range c.vector(terms) {
+			c.centroid[t] += w / float64(len(references))
+		}
+	}
+	normalize(c.centroid)
+	return c
+}
+
+func (c *Similarity) Classify(pageContent string) Result {
+	v := c.vector(termFrequencies(pageContent))
+	similarity := 0.0
+	for t, w := range v {
+		similarity += w * c.centroid[t]
+	}
+	return Result{
+		Score:   similarity,
+		Topical: similarity >= c.Threshold,
+	}
+}
+
+// vector converts term frequencies into a normalized TF-IDF vector.
+func (c *Similarity) vector(terms map[string]int) map[string]float64 {
+	v := make(map[string]float64, len(terms))
+	for t, tf := range terms {
+		idf, ok := c.idf[t]
+		if !ok {
+			idf = c.unknownIDF
+		}
+		v[t] = (1 + math.Log(float64(tf))) * idf
+	}
+	normalize(v)
+	return v
+}
+
+func termFrequencies(pageContent string) map[string]int {
+	terms := make(map[string]int)
+	for _, t := range analysis.Default.Terms(pageContent) {
+		terms[t]++
+	}
+	return terms
+}
+
+func normalize(v map[string]float64) {
+	norm := 0.0
+	for _, w := range v {
+		norm += w * w
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for t := range v {
+		v[t] /= norm
+	}
+}
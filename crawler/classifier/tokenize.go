@@ -0,0 +1,82 @@
+package classifier
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"strings"
+)
+
+// MatchOptions control how keywords are matched against the page.
+//
+// Both keywords and pages are split into words, so a keyword only matches
+// whole words: "go" doesn't match "good" or "Google". Matching is case
+// insensitive. Keywords in double quotes are phrases, their words need to
+// appear next to each other. Other keywords with multiple words match if
+// every word appears somewhere on the page.
+type MatchOptions struct {
+	// Stem enables English stemming of both keywords and page words, so that
+	// "crawling" matches "crawled".
+	Stem bool
+}
+
+func (o MatchOptions) terms(text string) []string {
+	analyzer := analysis.Default
+	if o.Stem {
+		analyzer = analyzer.With(analysis.Stem)
+	}
+	return analyzer.Terms(text)
+}
+
+// page is a page content prepared for keyword lookups.
+type page struct {
+	options MatchOptions
+	terms   []string
+	// Positions of each term in the page
+	positions map[string][]int
+}
+
+func newPage(pageContent string, options MatchOptions) page {
+	p := page{
+		options:   options,
+		terms:     options.terms(pageContent),
+		positions: make(map[string][]int),
+	}
+	for i, t := range p.terms {
+		p.positions[t] = append(p.positions[t], i)
+	}
+	return p
+}
+
+func (p page) contains(keyword string) bool {
+	keyword = strings.TrimSpace(keyword)
+	isPhrase := len(keyword) >= 2 && strings.HasPrefix(keyword, `"`) && strings.HasSuffix(keyword, `"`)
+	terms := p.options.terms(keyword)
+	if len(terms) == 0 {
+		return true
+	}
+	if !isPhrase {
+		for _, t := range terms {
+			if _, ok := p.positions[t]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	for _, start := range p.positions[terms[0]] {
+		if p.hasPhraseAt(start, terms) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p page) hasPhraseAt(start int, terms []string) bool {
+	if start+len(terms) > len(p.terms) {
+		return false
+	}
+	for i, t := range terms {
+		if p.terms[start+i] != t {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package classifier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Weighted classifier sums up weights of all keywords found on the page and
+// considers the page topical if the sum reaches the threshold. Negative
+// weights can be used for keywords that indicate an unrelated topic.
+type Weighted struct {
+	Weights   map[string]float64
+	Threshold float64
+	MatchOptions
+}
+
+func (c Weighted) Classify(pageContent string) Result {
+	p := newPage(pageContent, c.MatchOptions)
+	score := 0.0
+	for keyword, weight := range c.Weights {
+		if p.contains(keyword) {
+			score += weight
+		}
+	}
+	return Result{
+		Score:   score,
+		Topical: score >= c.Threshold,
+	}
+}
+
+// ParseWeights parses a comma-separated list of keywords with weights like
+// "golang:3,go:1,java:-2". Keywords without a weight get a weight of 1.
+func ParseWeights(s string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		keyword, weight := item, 1.0
+		if i := strings.LastIndex(item, ":"); i >= 0 {
+			var err error
+			keyword = item[:i]
+			weight, err = strconv.ParseFloat(item[i+1:], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid weight for keyword %q: %s", keyword, err)
+			}
+		}
+		weights[keyword] = weight
+	}
+	return weights, nil
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"go.roman.zone/crawl/crawler/classifier"
 	"go.roman.zone/crawl/crawler/html_cleaner"
+	"go.roman.zone/crawl/crawler/language"
 	"go.roman.zone/crawl/crawler/parser"
 	"go.roman.zone/crawl/index"
 	"log"
+	"mime"
 	"net/http"
 	"net/url"
+	"runtime"
 	"sync"
 	"time"
 )
@@ -17,12 +20,17 @@ import (
 const (
 	WORKER_COUNT          = 40
 	WORKER_SLEEP_TIME_SEC = 4 // seconds
+
+	// Maximum number of link texts that are kept for each page
+	MAX_ANCHOR_TEXTS = 20
 )
 
 var (
 	crawledPages   = make(map[url.URL]bool)
 	retrievedPages = make(map[url.URL]bool)
-	crawlMapLock   sync.Mutex
+	// Text of links to pages from the pages that were crawled
+	anchorTexts  = make(map[url.URL][]string)
+	crawlMapLock sync.Mutex
 
 	crawlQueue = newCrawlQueue()
 
@@ -32,12 +40,55 @@ var (
 	countLock       sync.Mutex
 )
 
-func Crawl(seedPage url.URL, topicKeywords []string, targetCount int, timeLimit time.Duration) []url.URL {
+// LanguageFilter restricts processing of pages to the ones written in certain
+// languages. Pages which language can't be determined are treated as pages in
+// other languages.
+type LanguageFilter struct {
+	// Language codes like "en" or "de". If empty, pages in all languages are
+	// processed.
+	Languages []string
+	// Only index pages in these languages
+	Index bool
+	// Only follow links from pages in these languages
+	Expand bool
+}
+
+func (f LanguageFilter) allows(lang string) bool {
+	return len(f.Languages) == 0 || language.Matches(lang, f.Languages)
+}
+
+// Config describes what to crawl and where to put the results.
+type Config struct {
+	Seed url.URL
+	// Only topical pages are added into the index
+	Topic     classifier.Classifier
+	Languages LanguageFilter
+	Index     index.Index
+	// Links found on retrieved pages are recorded into it, if it's set
+	Links *index.LinkGraph
+	// Number of unique pages to retrieve
+	TargetCount int
+	// Maximum time to crawl for, zero means no limit
+	TimeLimit time.Duration
+}
+
+// Crawl crawls pages starting from the seed and adds topical ones into the
+// index. Pages are indexed in the background, so that crawling doesn't wait
+// for the index. The index is not closed after crawling is done.
+func Crawl(config Config) []url.URL {
 	wg := new(sync.WaitGroup)
+	indexer := index.NewIndexer(config.Index, runtime.NumCPU(), func(page index.Page, err error) {
+		if err != nil {
+			log.Printf("Failed to index page %s: %s\n", page.URL.String(), err)
+			return
+		}
+		fmt.Println("Indexed page:", page.URL.String())
+	})
 
 	for i := 0; i <= WORKER_COUNT; i++ {
+		wg.Add(1)
 		go func(id int) {
-			wg.Add(1)
+			defer wg.Done()
 			for {
 				nextURL, err := crawlQueue.Pop()
 				if err != nil {
@@ -47,30 +98,28 @@ func Crawl(seedPage url.URL, topicKeywords []string, targetCount int, timeLimit
 					continue
 				}
 				crawlMapLock.Lock()
-				done := len(retrievedPages) >= targetCount
+				done := len(retrievedPages) >= config.TargetCount
 				crawlMapLock.Unlock()
 				if done {
-					wg.Done()
 					return
 				}
-				crawlPage(nextURL, id, topicKeywords)
+				crawlPage(nextURL, id, config, indexer)
 			}
-			wg.Done()
 		}(i + 1)
 	}
 
 	// Starting the process...
-	crawlQueue.Push(seedPage)
+	crawlQueue.Push(config.Seed)
 
-	if timeLimit > 0 {
-		time.Sleep(timeLimit)
+	if config.TimeLimit > 0 {
+		time.Sleep(config.TimeLimit)
 		fmt.Println("Time limit has been reached. Stopping crawling.")
 	} else {
 		wg.Wait()
 		fmt.Println("No more pages to crawl. Either limit has been reached or crawl queue is empty.")
 	}
+	indexer.Close()
 
-	index.Index.Export(index.STORAGE_FILE)
 	return getRetrievedURLs()
 }
 
@@ -88,7 +137,7 @@ func getRetrievedURLs() []url.URL {
 
 // TODO: Allow to pass a function for processing the pages. In the case of the final
 // project we need to pass a page for topic checking and indexing (done separately).
-func crawlPage(pageURL url.URL, workerID int, topicKeywords []string) {
+func crawlPage(pageURL url.URL, workerID int, config Config, indexer *index.Indexer) {
 	countLock.Lock()
 	if crawlCountTotal%100 == 0 {
 		crawlMapLock.Lock()
@@ -118,11 +167,15 @@ func crawlPage(pageURL url.URL, workerID int, topicKeywords []string) {
 		countLock.Lock()
 		ignoredCount++
 		countLock.Unlock()
+		if err == nil {
+			// Page might have been indexed before robots.txt disallowed it
+			removeFromIndex(pageURL, workerID, config)
+		}
 		return
 	}
 
 	// Retrieving the page, parsing, etc.
-	pageContent, err := GetPage(pageURL)
+	resp, err := Fetch(pageURL)
 	crawlMapLock.Lock()
 	retrievedPages[pageURL] = true
 	crawlMapLock.Unlock()
@@ -131,25 +184,93 @@ func crawlPage(pageURL url.URL, workerID int, topicKeywords []string) {
 			workerID, pageURL.String(), err)
 		return
 	}
-	linksToQueue(pageContent) // extracting links before indexing to not slow down the process
-	if classifier.IsTopical(html_cleaner.Clean(pageContent), topicKeywords) {
-		index.ProcessPage(index.Page{URL: pageURL, Content: pageContent})
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		// Page might have been indexed during one of the previous crawls
+		removeFromIndex(pageURL, workerID, config)
+		return
+	case resp.StatusCode >= 400:
+		return
+	}
+	doc := html_cleaner.Parse(resp.Content)
+	text := doc.Text()
+	lang := language.Detect(resp.Content, resp.Header, text)
+	isAllowedLanguage := config.Languages.allows(lang)
+	if isAllowedLanguage || !config.Languages.Expand {
+		links := linksToQueue(resp.Content) // extracting links before indexing to not slow down the process
+		if config.Links != nil {
+			if err := config.Links.SetLinks(pageURL, links); err != nil {
+				log.Printf("Worker %d: Failed to record links of %s: %s\n",
+					workerID, pageURL.String(), err)
+			}
+		}
+	}
+	if !isAllowedLanguage && config.Languages.Index {
+		return
+	}
+	if IsNoIndex(resp.Content, resp.Header) {
+		removeFromIndex(pageURL, workerID, config)
+		return
+	}
+	if config.Topic.Classify(text).Topical {
+		crawlMapLock.Lock()
+		anchors := anchorTexts[pageURL]
+		crawlMapLock.Unlock()
+		err := indexer.Add(index.Page{
+			URL:         pageURL,
+			Content:     text,
+			Title:       doc.Title(),
+			Headings:    doc.Headings(),
+			Anchors:     anchors,
+			Language:    lang,
+			ContentType: resp.ContentType(),
+			FetchTime:   time.Now(),
+		})
+		if err != nil {
+			log.Printf("Worker %d: Failed to index page %s: %s\n",
+				workerID, pageURL.String(), err)
+		}
 	}
 }
 
+// removeFromIndex deletes a page that shouldn't be in the index anymore.
+func removeFromIndex(pageURL url.URL, workerID int, config Config) {
+	if _, err := config.Index.Document(pageURL); err != nil {
+		return
+	}
+	if err := config.Index.Delete(pageURL); err != nil {
+		log.Printf("Worker %d: Failed to delete page %s from the index: %s\n",
+			workerID, pageURL.String(), err)
+		return
+	}
+	fmt.Println("Deleted page:", pageURL.String())
+}
+
 // linksToQueue does link extraction from an HTML page and puts all uncrawled
-// URLs into the crawl queue.
-func linksToQueue(pageContent string) {
-	urls, err := parser.GetAllURLs(pageContent)
+// URLs into the crawl queue. Text of the links is recorded so that it can be
+// indexed along with the pages they point to. It returns URLs of all the
+// links, so that they can be stored in the link graph.
+func linksToQueue(pageContent string) []url.URL {
+	links, err := parser.GetAllLinks(pageContent)
 	if err != nil {
 		log.Printf("Failed to extract links: %s\n", err)
-		return
+		return nil
 	}
-	for _, u := range urls {
-		if !isCrawled(u) {
-			crawlQueue.Push(u)
+	urls := make([]url.URL, len(links))
+	for i, l := range links {
+		urls[i] = l.URL
+		if l.Text != "" {
+			crawlMapLock.Lock()
+			if len(anchorTexts[l.URL]) < MAX_ANCHOR_TEXTS {
+				anchorTexts[l.URL] = append(anchorTexts[l.URL], l.Text)
+			}
+			crawlMapLock.Unlock()
+		}
+		if !isCrawled(l.URL) {
+			crawlQueue.Push(l.URL)
 		}
 	}
+	return urls
 }
 
 func isCrawled(pageURL url.URL) bool {
@@ -164,13 +285,46 @@ func isCrawled(pageURL url.URL) bool {
 	return found && isCrawled
 }
 
-func GetPage(pageURL url.URL) (string, error) {
+// Response is a retrieved page.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Content    string
+}
+
+// ContentType returns the MIME type of the content without parameters. If the
+// server didn't specify it, it's detected from the content.
+func (r *Response) ContentType() string {
+	header := r.Header.Get("Content-Type")
+	if header == "" {
+		header = http.DetectContentType([]byte(r.Content))
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+func Fetch(pageURL url.URL) (*Response, error) {
 	resp, err := http.Get(pageURL.String())
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 	buf := new(bytes.Buffer)
 	buf.ReadFrom(resp.Body)
-	return buf.String(), nil
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Content:    buf.String(),
+	}, nil
+}
+
+func GetPage(pageURL url.URL) (string, error) {
+	resp, err := Fetch(pageURL)
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
 }
@@ -2,7 +2,8 @@ package html_cleaner
 
 // Clean cleans up HTML page for further processing.
 //
-// Currently it just removes all the tags from the page.
+// It removes all the tags, as well as contents of scripts and styles, from the
+// page. Use Parse if you need to know where the text came from.
 func Clean(pageContent string) string {
-	return StripTags(pageContent)
+	return Parse(pageContent).Text()
 }
@@ -8,3 +8,33 @@ func TestClean(t *testing.T) {
 		t.Error("Didn't clean the HTML properly")
 	}
 }
+
+func TestParse(t *testing.T) {
+	htmlString := `<html><head><title>Page title</title><script>var x;</script></head>
+<body><h1>Main heading</h1><p>Some <em>important</em> text.<p>Another one.
+<ul><li>First<li>Second</ul><p>Before <img src="a.png" alt="A picture"> after</p></body></html>`
+	expected := []Section{
+		{FIELD_TITLE, "Page title"},
+		{FIELD_H1, "Main heading"},
+		{FIELD_EMPHASIS, "important"},
+		{FIELD_PARAGRAPH, "Some important text."},
+		{FIELD_PARAGRAPH, "Another one."},
+		{FIELD_LIST, "First"},
+		{FIELD_LIST, "Second"},
+		{FIELD_PARAGRAPH, "Before"},
+		{FIELD_ALT, "A picture"},
+		{FIELD_PARAGRAPH, "after"},
+	}
+	doc := Parse(htmlString)
+	if len(doc.Sections) != len(expected) {
+		t.Fatalf("Expected %d sections, got %d: %v", len(expected), len(doc.Sections), doc.Sections)
+	}
+	for i, s := range expected {
+		if doc.Sections[i] != s {
+			t.Errorf("Section %d: expected %v, got %v", i, s, doc.Sections[i])
+		}
+	}
+	if doc.Title() != "Page title" {
+		t.Error("Didn't extract the title")
+	}
+}
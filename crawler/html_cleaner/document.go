@@ -0,0 +1,276 @@
+package html_cleaner
+
+import (
+	"golang.org/x/net/html"
+	"strings"
+)
+
+// Field identifies the part of an HTML page that a piece of text came from.
+type Field string
+
+const (
+	FIELD_TITLE     Field = "title"
+	FIELD_H1        Field = "h1"
+	FIELD_H2        Field = "h2"
+	FIELD_H3        Field = "h3"
+	FIELD_H4        Field = "h4"
+	FIELD_H5        Field = "h5"
+	FIELD_H6        Field = "h6"
+	FIELD_PARAGRAPH Field = "paragraph"
+	FIELD_LIST      Field = "list"
+	FIELD_TABLE     Field = "table"
+	FIELD_ALT       Field = "alt"
+	FIELD_EMPHASIS  Field = "emphasis"
+	FIELD_BODY      Field = "body" // Text that doesn't belong to any other field
+)
+
+// IsHeading reports whether the field is one of h1-h6.
+func (f Field) IsHeading() bool {
+	switch f {
+	case FIELD_H1, FIELD_H2, FIELD_H3, FIELD_H4, FIELD_H5, FIELD_H6:
+		return true
+	}
+	return false
+}
+
+// Section is a continuous piece of text from a single field of the page.
+type Section struct {
+	Field Field
+	Text  string
+}
+
+// Document is a segmented representation of an HTML page. Sections are kept
+// in the order they appear on the page.
+//
+// Emphasised text (<em>, <strong>, etc.) is recorded twice: as a part of the
+// enclosing section and as a separate FIELD_EMPHASIS section that is placed
+// right before it.
+type Document struct {
+	Sections []Section
+}
+
+// Title returns the contents of the <title> element.
+func (d Document) Title() string {
+	return d.FieldText(FIELD_TITLE)
+}
+
+// Headings returns text of all headings.
+func (d Document) Headings() []string {
+	headings := make([]string, 0)
+	for _, s := range d.Sections {
+		if s.Field.IsHeading() {
+			headings = append(headings, s.Text)
+		}
+	}
+	return headings
+}
+
+// FieldText returns text of all sections of a given field.
+func (d Document) FieldText(field Field) string {
+	parts := make([]string, 0)
+	for _, s := range d.Sections {
+		if s.Field == field {
+			parts = append(parts, s.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Text returns text of the whole document with every section on a separate
+// line. Emphasis sections are skipped since their text is already a part of
+// other sections.
+func (d Document) Text() string {
+	parts := make([]string, 0, len(d.Sections))
+	for _, s := range d.Sections {
+		if s.Field == FIELD_EMPHASIS {
+			continue
+		}
+		parts = append(parts, s.Text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+var (
+	// Elements that map directly to a field.
+	fieldElements = map[string]Field{
+		"title":   FIELD_TITLE,
+		"h1":      FIELD_H1,
+		"h2":      FIELD_H2,
+		"h3":      FIELD_H3,
+		"h4":      FIELD_H4,
+		"h5":      FIELD_H5,
+		"h6":      FIELD_H6,
+		"p":       FIELD_PARAGRAPH,
+		"li":      FIELD_LIST,
+		"dt":      FIELD_LIST,
+		"dd":      FIELD_LIST,
+		"td":      FIELD_TABLE,
+		"th":      FIELD_TABLE,
+		"caption": FIELD_TABLE,
+	}
+
+	// Elements that end a section, but don't define a field of their own.
+	blockElements = map[string]bool{
+		"address": true, "article": true, "aside": true, "blockquote": true,
+		"body": true, "br": true, "div": true, "dl": true, "figcaption": true,
+		"figure": true, "footer": true, "form": true, "head": true,
+		"header": true, "hr": true, "html": true, "main": true, "nav": true,
+		"ol": true, "pre": true, "section": true, "table": true, "tr": true,
+		"ul": true,
+	}
+
+	emphasisElements = map[string]bool{
+		"b": true, "em": true, "i": true, "mark": true, "strong": true,
+	}
+
+	// Elements which contents are never displayed as text.
+	skippedElements = map[string]bool{
+		"noscript": true, "script": true, "style": true, "template": true,
+	}
+)
+
+type openField struct {
+	tag   string
+	field Field
+}
+
+type documentBuilder struct {
+	doc      Document
+	fields   []openField
+	text     strings.Builder
+	emphasis strings.Builder
+	emDepth  int
+	skip     int
+}
+
+// Parse splits an HTML page into sections based on the elements that contain
+// the text.
+func Parse(pageContent string) Document {
+	b := &documentBuilder{}
+	tokenizer := html.NewTokenizer(strings.NewReader(pageContent))
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case html.ErrorToken:
+			b.flush()
+			b.flushEmphasis()
+			return b.doc
+		case html.TextToken:
+			if b.skip == 0 {
+				b.write(string(tokenizer.Text()))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := tokenizer.Token()
+			b.startTag(t, tt == html.SelfClosingTagToken)
+		case html.EndTagToken:
+			t := tokenizer.Token()
+			b.endTag(t.Data)
+		}
+	}
+}
+
+func (b *documentBuilder) startTag(t html.Token, selfClosing bool) {
+	switch {
+	case skippedElements[t.Data]:
+		if !selfClosing {
+			b.skip++
+		}
+	case t.Data == "img" || (t.Data == "input" && tokenAttr(t, "type") == "image"):
+		if alt := normalizeSpace(tokenAttr(t, "alt")); alt != "" {
+			// Text before the image goes first
+			b.flush()
+			b.doc.Sections = append(b.doc.Sections, Section{Field: FIELD_ALT, Text: alt})
+		}
+	case emphasisElements[t.Data]:
+		if !selfClosing {
+			b.emDepth++
+		}
+	default:
+		field, isField := fieldElements[t.Data]
+		if !isField && !blockElements[t.Data] {
+			return // Inline element
+		}
+		b.flush()
+		if !isField || selfClosing {
+			return
+		}
+		// Some elements are often left unclosed, e.g. <p> or <li>.
+		if len(b.fields) > 0 && b.fields[len(b.fields)-1].tag == t.Data &&
+			(t.Data == "p" || t.Data == "li" || t.Data == "td" || t.Data == "th") {
+			b.fields = b.fields[:len(b.fields)-1]
+		}
+		b.fields = append(b.fields, openField{tag: t.Data, field: field})
+	}
+}
+
+func (b *documentBuilder) endTag(tag string) {
+	switch {
+	case skippedElements[tag]:
+		if b.skip > 0 {
+			b.skip--
+		}
+	case emphasisElements[tag]:
+		if b.emDepth > 0 {
+			b.emDepth--
+		}
+		if b.emDepth == 0 {
+			b.flushEmphasis()
+		}
+	case fieldElements[tag] != "":
+		b.flush()
+		for i := len(b.fields) - 1; i >= 0; i-- {
+			if b.fields[i].tag == tag {
+				b.fields = b.fields[:i]
+				break
+			}
+		}
+	case blockElements[tag]:
+		b.flush()
+	}
+}
+
+func (b *documentBuilder) write(text string) {
+	b.text.WriteString(text)
+	if b.emDepth > 0 {
+		b.emphasis.WriteString(text)
+	}
+}
+
+func (b *documentBuilder) currentField() Field {
+	if len(b.fields) == 0 {
+		return FIELD_BODY
+	}
+	return b.fields[len(b.fields)-1].field
+}
+
+// flush ends the current section.
+func (b *documentBuilder) flush() {
+	text := normalizeSpace(b.text.String())
+	b.text.Reset()
+	if text == "" {
+		return
+	}
+	b.doc.Sections = append(b.doc.Sections, Section{Field: b.currentField(), Text: text})
+}
+
+func (b *documentBuilder) flushEmphasis() {
+	text := normalizeSpace(b.emphasis.String())
+	b.emphasis.Reset()
+	if text == "" {
+		return
+	}
+	b.doc.Sections = append(b.doc.Sections, Section{Field: FIELD_EMPHASIS, Text: text})
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func tokenAttr(t html.Token, key string) string {
+	for _, a := range t.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
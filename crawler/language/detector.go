@@ -0,0 +1,177 @@
+package language
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	PROFILE_SIZE = 300 // Number of most frequent n-grams kept in a profile
+
+	// Texts with fewer letters are too short for reliable detection
+	MIN_TEXT_LETTERS = 20
+	// Only this many letters from the beginning of a text are used
+	MAX_TEXT_LETTERS = 10000
+)
+
+var (
+	profiles = buildProfiles()
+
+	// Scripts that are only used by a single language (as far as this
+	// detector is concerned).
+	scriptLanguages = []struct {
+		script *unicode.RangeTable
+		lang   string
+	}{
+		{unicode.Hangul, "ko"},
+		{unicode.Hiragana, "ja"},
+		{unicode.Katakana, "ja"},
+		{unicode.Han, "zh"},
+		{unicode.Greek, "el"},
+		{unicode.Hebrew, "he"},
+		{unicode.Arabic, "ar"},
+		{unicode.Thai, "th"},
+		{unicode.Devanagari, "hi"},
+		{unicode.Armenian, "hy"},
+		{unicode.Georgian, "ka"},
+	}
+)
+
+// profile maps n-grams to their rank (0 is the most frequent one).
+type profile map[string]int
+
+// DetectText identifies the language of a text using n-gram frequency
+// profiles (see Cavnar and Trenkle, "N-Gram-Based Text Categorization").
+// Confidence is between 0 and 1.
+func DetectText(text string) (lang string, confidence float64) {
+	letters := 0
+	scriptCounts := make(map[string]int)
+	latinOrCyrillic := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.In(r, unicode.Latin, unicode.Cyrillic) {
+			latinOrCyrillic++
+		} else {
+			for _, s := range scriptLanguages {
+				if unicode.Is(s.script, r) {
+					scriptCounts[s.lang]++
+					break
+				}
+			}
+		}
+		if letters >= MAX_TEXT_LETTERS {
+			break
+		}
+	}
+	if letters < MIN_TEXT_LETTERS {
+		return "", 0
+	}
+
+	// Japanese text is mostly Han characters with some kana.
+	if scriptCounts["ja"] > 0 && scriptCounts["ja"]*10 >= scriptCounts["zh"] {
+		scriptCounts["ja"] += scriptCounts["zh"]
+		delete(scriptCounts, "zh")
+	}
+	best, bestCount := "", 0
+	for l, count := range scriptCounts {
+		if count > bestCount {
+			best, bestCount = l, count
+		}
+	}
+	if bestCount > latinOrCyrillic {
+		return best, float64(bestCount) / float64(letters)
+	}
+
+	return detectByProfile(newProfile(text))
+}
+
+func detectByProfile(p profile) (string, float64) {
+	maxDistance := PROFILE_SIZE * len(p)
+	best, bestDistance := "", maxDistance
+	secondDistance := maxDistance
+	for lang, langProfile := range profiles {
+		d := distance(p, langProfile)
+		if d < bestDistance {
+			best, secondDistance, bestDistance = lang, bestDistance, d
+		} else if d < secondDistance {
+			secondDistance = d
+		}
+	}
+	if best == "" || secondDistance == 0 {
+		return "", 0
+	}
+	// Confidence depends on how much closer the best match is compared to the
+	// second best one.
+	return best, float64(secondDistance-bestDistance) / float64(secondDistance)
+}
+
+// distance is the "out-of-place" measure between two profiles.
+func distance(text, lang profile) int {
+	d := 0
+	for gram, rank := range text {
+		langRank, ok := lang[gram]
+		if !ok {
+			d += PROFILE_SIZE
+			continue
+		}
+		if rank > langRank {
+			d += rank - langRank
+		} else {
+			d += langRank - rank
+		}
+	}
+	return d
+}
+
+// newProfile counts n-grams of length 1 to 3 in every word of a text and keeps
+// the most frequent ones.
+func newProfile(text string) profile {
+	counts := make(map[string]int)
+	letters := 0
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		runes := []rune(" " + strings.ToLower(word) + " ")
+		for n := 1; n <= 3; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				gram := string(runes[i : i+n])
+				if gram != " " {
+					counts[gram]++
+				}
+			}
+		}
+		letters += len(runes) - 2
+		if letters >= MAX_TEXT_LETTERS {
+			break
+		}
+	}
+
+	grams := make([]string, 0, len(counts))
+	for gram := range counts {
+		grams = append(grams, gram)
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if counts[grams[i]] != counts[grams[j]] {
+			return counts[grams[i]] > counts[grams[j]]
+		}
+		return grams[i] < grams[j]
+	})
+	if len(grams) > PROFILE_SIZE {
+		grams = grams[:PROFILE_SIZE]
+	}
+	p := make(profile, len(grams))
+	for rank, gram := range grams {
+		p[gram] = rank
+	}
+	return p
+}
+
+func buildProfiles() map[string]profile {
+	p := make(map[string]profile, len(samples))
+	for lang, text := range samples {
+		p[lang] = newProfile(text)
+	}
+	return p
+}
@@ -0,0 +1,87 @@
+// Package language identifies the language of web pages.
+//
+// Languages are represented by ISO 639-1 codes, like "en" or "de". Empty
+// string means that the language is unknown.
+package language
+
+import (
+	"golang.org/x/net/html"
+	"net/http"
+	"strings"
+)
+
+// Detect determines the language of a page. Languages declared by the page
+// itself (in the lang attribute of the <html> element) or by the server (in the
+// Content-Language header) are preferred. Text of the page is only used when
+// the language isn't declared.
+func Detect(pageContent string, header http.Header, text string) string {
+	if lang := FromHTML(pageContent); lang != "" {
+		return lang
+	}
+	if lang := FromHeader(header); lang != "" {
+		return lang
+	}
+	lang, _ := DetectText(text)
+	return lang
+}
+
+// FromHTML returns the language declared in the lang attribute of the <html>
+// element.
+func FromHTML(pageContent string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(pageContent))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := tokenizer.Token()
+			if t.Data == "body" {
+				return ""
+			}
+			if t.Data != "html" {
+				continue
+			}
+			for _, a := range t.Attr {
+				if a.Key == "lang" || a.Key == "xml:lang" {
+					return Normalize(a.Val)
+				}
+			}
+			return ""
+		}
+	}
+}
+
+// FromHeader returns the first language listed in the Content-Language header.
+func FromHeader(header http.Header) string {
+	if header == nil {
+		return ""
+	}
+	return Normalize(strings.Split(header.Get("Content-Language"), ",")[0])
+}
+
+// Normalize converts a language tag like "en-US" into a lower case language
+// code without a region ("en").
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if len(tag) < 2 || len(tag) > 3 {
+		return ""
+	}
+	return tag
+}
+
+// Matches reports whether the language is one of the listed ones. Unknown
+// language doesn't match anything.
+func Matches(lang string, languages []string) bool {
+	if lang == "" {
+		return false
+	}
+	for _, l := range languages {
+		if Normalize(l) == lang {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package language
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDetectText(t *testing.T) {
+	cases := map[string]string{
+		"The quick brown fox jumps over the lazy dog while the children are playing outside.":         "en",
+		"Der schnelle braune Fuchs springt über den faulen Hund, während die Kinder draußen spielen.": "de",
+		"Le renard brun rapide saute par-dessus le chien paresseux pendant que les enfants jouent.":   "fr",
+		"El rápido zorro marrón salta sobre el perro perezoso mientras los niños juegan afuera.":      "es",
+		"Быстрая коричневая лиса прыгает через ленивую собаку, пока дети играют на улице.":            "ru",
+		"これは日本語で書かれた文章です。ひらがなとカタカナと漢字が使われています。":                                                       "ja",
+	}
+	for text, expected := range cases {
+		if lang, _ := DetectText(text); lang != expected {
+			t.Errorf("Expected %q, got %q for %q", expected, lang, text)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Language", "de-DE, en")
+	if lang := Detect(`<html lang="fr-CA"><body>Text</body></html>`, header, "Text"); lang != "fr" {
+		t.Errorf("Expected language from the <html> element, got %q", lang)
+	}
+	if lang := Detect(`<html><body>Text</body></html>`, header, "Text"); lang != "de" {
+		t.Errorf("Expected language from the header, got %q", lang)
+	}
+}
@@ -0,0 +1,136 @@
+package language
+
+// Sample texts that language profiles are built from. Mostly based on the
+// Universal Declaration of Human Rights, which is available in every language.
+var samples = map[string]string{
+	"en": `All human beings are born free and equal in dignity and rights. They are
+endowed with reason and conscience and should act towards one another in a
+spirit of brotherhood. Everyone is entitled to all the rights and freedoms set
+forth in this Declaration, without distinction of any kind, such as race,
+colour, sex, language, religion, political or other opinion, national or social
+origin, property, birth or other status. Everyone has the right to life,
+liberty and security of person. No one shall be held in slavery or servitude.
+The weather was nice, so we went for a walk and then had dinner with our
+friends. What do you think about this? There is nothing that would be more
+important than the things which we have learned from each other.`,
+
+	"de": `Alle Menschen sind frei und gleich an Würde und Rechten geboren. Sie sind
+mit Vernunft und Gewissen begabt und sollen einander im Geist der
+Brüderlichkeit begegnen. Jeder hat Anspruch auf alle in dieser Erklärung
+verkündeten Rechte und Freiheiten ohne irgendeinen Unterschied, etwa nach
+Rasse, Hautfarbe, Geschlecht, Sprache, Religion, politischer oder sonstiger
+Überzeugung, nationaler oder sozialer Herkunft, Vermögen, Geburt oder sonstigem
+Stand. Jeder hat das Recht auf Leben, Freiheit und Sicherheit der Person.
+Niemand darf in Sklaverei oder Leibeigenschaft gehalten werden. Das Wetter war
+schön, deshalb sind wir spazieren gegangen und haben danach mit unseren
+Freunden zu Abend gegessen. Was denkst du darüber? Es gibt nichts, was
+wichtiger wäre als die Dinge, die wir voneinander gelernt haben.`,
+
+	"fr": `Tous les êtres humains naissent libres et égaux en dignité et en droits. Ils
+sont doués de raison et de conscience et doivent agir les uns envers les autres
+dans un esprit de fraternité. Chacun peut se prévaloir de tous les droits et de
+toutes les libertés proclamés dans la présente Déclaration, sans distinction
+aucune, notamment de race, de couleur, de sexe, de langue, de religion,
+d'opinion politique ou de toute autre opinion, d'origine nationale ou sociale,
+de fortune, de naissance ou de toute autre situation. Tout individu a droit à
+la vie, à la liberté et à la sûreté de sa personne. Nul ne sera tenu en
+esclavage ni en servitude. Il faisait beau, alors nous sommes allés nous
+promener et ensuite nous avons dîné avec nos amis. Qu'est-ce que tu en penses?
+Il n'y a rien de plus important que les choses que nous avons apprises les uns
+des autres.`,
+
+	"es": `Todos los seres humanos nacen libres e iguales en dignidad y derechos y,
+dotados como están de razón y conciencia, deben comportarse fraternalmente los
+unos con los otros. Toda persona tiene todos los derechos y libertades
+proclamados en esta Declaración, sin distinción alguna de raza, color, sexo,
+idioma, religión, opinión política o de cualquier otra índole, origen nacional
+o social, posición económica, nacimiento o cualquier otra condición. Todo
+individuo tiene derecho a la vida, a la libertad y a la seguridad de su
+persona. Nadie estará sometido a esclavitud ni a servidumbre. Hacía buen
+tiempo, así que salimos a pasear y después cenamos con nuestros amigos. ¿Qué
+piensas de esto? No hay nada más importante que las cosas que hemos aprendido
+los unos de los otros.`,
+
+	"it": `Tutti gli esseri umani nascono liberi ed eguali in dignità e diritti. Essi
+sono dotati di ragione e di coscienza e devono agire gli uni verso gli altri in
+spirito di fratellanza. Ad ogni individuo spettano tutti i diritti e tutte le
+libertà enunciate nella presente Dichiarazione, senza distinzione alcuna, per
+ragioni di razza, di colore, di sesso, di lingua, di religione, di opinione
+politica o di altro genere, di origine nazionale o sociale, di ricchezza, di
+nascita o di altra condizione. Ogni individuo ha diritto alla vita, alla
+libertà ed alla sicurezza della propria persona. Nessun individuo potrà essere
+tenuto in stato di schiavitù o di servitù. Il tempo era bello, quindi siamo
+andati a fare una passeggiata e poi abbiamo cenato con i nostri amici. Che cosa
+ne pensi? Non c'è niente di più importante delle cose che abbiamo imparato gli
+uni dagli altri.`,
+
+	"pt": `Todos os seres humanos nascem livres e iguais em dignidade e em direitos.
+Dotados de razão e de consciência, devem agir uns para com os outros em
+espírito de fraternidade. Todos os seres humanos podem invocar os direitos e as
+liberdades proclamados na presente Declaração, sem distinção alguma,
+nomeadamente de raça, de cor, de sexo, de língua, de religião, de opinião
+política ou outra, de origem nacional ou social, de fortuna, de nascimento ou
+de qualquer outra situação. Todo o indivíduo tem direito à vida, à liberdade e
+à segurança pessoal. Ninguém será mantido em escravatura ou em servidão. O
+tempo estava bom, então fomos passear e depois jantamos com os nossos amigos. O
+que você acha disso? Não há nada mais importante do que as coisas que
+aprendemos uns com os outros.`,
+
+	"nl": `Alle mensen worden vrij en gelijk in waardigheid en rechten geboren. Zij zijn
+begiftigd met verstand en geweten, en behoren zich jegens elkander in een geest
+van broederschap te gedragen. Een ieder heeft aanspraak op alle rechten en
+vrijheden, in deze Verklaring opgesomd, zonder enig onderscheid van welke aard
+ook, zoals ras, kleur, geslacht, taal, godsdienst, politieke of andere
+overtuiging, nationale of maatschappelijke afkomst, eigendom, geboorte of
+andere status. Een ieder heeft het recht op leven, vrijheid en veiligheid van
+zijn persoon. Niemand zal in slavernij of dienstbaarheid gehouden worden. Het
+weer was mooi, dus we gingen wandelen en daarna hebben we met onze vrienden
+gegeten. Wat vind jij daarvan? Er is niets belangrijker dan de dingen die we
+van elkaar hebben geleerd.`,
+
+	"sv": `Alla människor är födda fria och lika i värde och rättigheter. De har
+utrustats med förnuft och samvete och bör handla gentemot varandra i en anda av
+broderskap. Var och en är berättigad till alla de fri- och rättigheter som
+uttalas i denna förklaring utan åtskillnad av något slag, såsom ras, hudfärg,
+kön, språk, religion, politisk eller annan åskådning, nationellt eller socialt
+ursprung, egendom, börd eller ställning i övrigt. Var och en har rätt till
+liv, frihet och personlig säkerhet. Ingen får hållas i slaveri eller träldom.
+Vädret var fint, så vi gick en promenad och sedan åt vi middag med våra
+vänner. Vad tycker du om det? Det finns ingenting som är viktigare än det som
+vi har lärt oss av varandra.`,
+
+	"pl": `Wszyscy ludzie rodzą się wolni i równi pod względem swej godności i swych
+praw. Są oni obdarzeni rozumem i sumieniem i powinni postępować wobec innych w
+duchu braterstwa. Każdy człowiek posiada wszystkie prawa i wolności zawarte w
+niniejszej Deklaracji bez względu na różnice rasy, koloru skóry, płci, języka,
+wyznania, poglądów politycznych i innych przekonań, narodowości, pochodzenia
+społecznego, majątku, urodzenia lub jakiegokolwiek innego stanu. Każdy
+człowiek ma prawo do życia, wolności i bezpieczeństwa swej osoby. Nikt nie
+może być trzymany w niewolnictwie lub w poddaństwie. Pogoda była ładna, więc
+poszliśmy na spacer, a potem zjedliśmy kolację z przyjaciółmi. Co o tym
+myślisz? Nie ma nic ważniejszego niż rzeczy, których nauczyliśmy się od
+siebie nawzajem.`,
+
+	"ru": `Все люди рождаются свободными и равными в своем достоинстве и правах. Они
+наделены разумом и совестью и должны поступать в отношении друг друга в духе
+братства. Каждый человек должен обладать всеми правами и всеми свободами,
+провозглашенными настоящей Декларацией, без какого бы то ни было различия, как
+то в отношении расы, цвета кожи, пола, языка, религии, политических или иных
+убеждений, национального или социального происхождения, имущественного,
+сословного или иного положения. Каждый человек имеет право на жизнь, на
+свободу и на личную неприкосновенность. Никто не должен содержаться в рабстве
+или в подневольном состоянии. Погода была хорошая, поэтому мы пошли гулять, а
+потом поужинали с нашими друзьями. Что ты об этом думаешь? Нет ничего важнее
+того, чему мы научились друг у друга.`,
+
+	"uk": `Всі люди народжуються вільними і рівними у своїй гідності та правах. Вони
+наділені розумом і совістю і повинні діяти у відношенні один до одного в дусі
+братерства. Кожна людина повинна мати всі права і всі свободи, проголошені
+цією Декларацією, незалежно від раси, кольору шкіри, статі, мови, релігії,
+політичних або інших переконань, національного чи соціального походження,
+майнового, станового або іншого становища. Кожна людина має право на життя, на
+свободу і на особисту недоторканність. Ніхто не повинен бути в рабстві або в
+підневільному стані. Погода була гарна, тому ми пішли гуляти, а потім
+повечеряли з нашими друзями. Що ти про це думаєш? Немає нічого важливішого за
+те, чого ми навчилися одне в одного.`,
+}
@@ -0,0 +1,73 @@
+package crawler
+
+import (
+	"bufio"
+	"go.roman.zone/crawl/crawler/html_cleaner"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadPages reads pages saved in a directory (one page per file) and returns
+// their cleaned up text.
+func ReadPages(dir string) ([]string, error) {
+	pages := make([]string, 0)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, html_cleaner.Clean(string(content)))
+		return nil
+	})
+	return pages, err
+}
+
+// FetchPages retrieves pages listed in a file (one URL per line) and returns
+// their cleaned up text. Pages that can't be retrieved are skipped.
+func FetchPages(listFile string) ([]string, error) {
+	f, err := os.Open(listFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pages := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pageURL, err := url.Parse(line)
+		if err != nil {
+			return nil, err
+		}
+		content, err := GetPage(*pageURL)
+		if err != nil {
+			log.Printf("Failed to retrieve %s: %s\n", line, err)
+			continue
+		}
+		pages = append(pages, html_cleaner.Clean(content))
+	}
+	return pages, scanner.Err()
+}
+
+// LoadPages reads pages from a directory or, if path is a file, fetches pages
+// listed in it.
+func LoadPages(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return ReadPages(path)
+	}
+	return FetchPages(path)
+}
@@ -8,22 +8,53 @@ import (
 	"strings"
 )
 
+// Link is a link found on a page.
+type Link struct {
+	URL url.URL
+	// Text inside of the <a> element
+	Text string
+}
+
 // GetAllURLs retrieves all URLs from an HTML page.
 func GetAllURLs(pageContent string) ([]url.URL, error) {
-	var urls []url.URL
+	links, err := GetAllLinks(pageContent)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]url.URL, len(links))
+	for i, l := range links {
+		urls[i] = l.URL
+	}
+	return urls, nil
+}
+
+// GetAllLinks retrieves all links to HTTP(S) URLs along with their text from
+// an HTML page.
+func GetAllLinks(pageContent string) ([]Link, error) {
+	var links []Link
+	// Index of the link which text is being read
+	current := -1
 
 	tokenizer := html.NewTokenizer(bytes.NewReader([]byte(pageContent)))
 	for {
 		tt := tokenizer.Next()
 		switch {
 		case tt == html.ErrorToken:
-			return urls, nil
+			return links, nil
+		case tt == html.TextToken && current >= 0:
+			links[current].Text += string(tokenizer.Text())
+		case tt == html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "a" && current >= 0 {
+				links[current].Text = strings.Join(strings.Fields(links[current].Text), " ")
+				current = -1
+			}
 		case tt == html.StartTagToken:
 			t := tokenizer.Token()
 			isAnchor := t.Data == "a"
 			if !isAnchor {
 				continue
 			}
+			current = -1
 			link, err := extractLink(t)
 			if err != nil {
 				continue
@@ -35,10 +66,10 @@ func GetAllURLs(pageContent string) ([]url.URL, error) {
 			if !(strings.EqualFold(u.Scheme, "HTTPS") || strings.EqualFold(u.Scheme, "HTTP")) {
 				continue
 			}
-			urls = append(urls, *u)
+			links = append(links, Link{URL: *u})
+			current = len(links) - 1
 		}
 	}
-	return urls, nil
 }
 
 func extractLink(t html.Token) (string, error) {
@@ -2,9 +2,11 @@ package crawler
 
 import (
 	"github.com/temoto/robotstxt"
+	"golang.org/x/net/html"
 	"log"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 )
@@ -15,6 +17,15 @@ var (
 
 	robotsDataCache  = make(map[string]*robotstxt.RobotsData)
 	robotsCacheMutex sync.Mutex
+
+	// Robots directives that have a value after a colon, so they can look
+	// like a user agent
+	valueDirectives = map[string]bool{
+		"unavailable_after": true,
+		"max-snippet":       true,
+		"max-image-preview": true,
+		"max-video-preview": true,
+	}
 )
 
 const (
@@ -85,3 +96,62 @@ func GetRobotsData(host string) (*robotstxt.RobotsData, error) {
 	robotsCacheMutex.Unlock()
 	return data, nil
 }
+
+// IsNoIndex reports whether a page asks not to be indexed in the X-Robots-Tag
+// header or in a robots <meta> element.
+func IsNoIndex(pageContent string, header http.Header) bool {
+	for _, value := range header.Values("X-Robots-Tag") {
+		// Directives can be prefixed with a user agent, like "googlebot: noindex"
+		if i := strings.Index(value, ":"); i >= 0 {
+			agent := strings.TrimSpace(value[:i])
+			if !strings.ContainsAny(agent, ", ") && !valueDirectives[strings.ToLower(agent)] {
+				if !strings.EqualFold(agent, USER_AGENT) {
+					continue
+				}
+				value = value[i+1:]
+			}
+		}
+		if hasNoIndex(value) {
+			return true
+		}
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(pageContent))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := tokenizer.Token()
+			if t.Data == "body" {
+				return false
+			}
+			if t.Data != "meta" {
+				continue
+			}
+			var name, content string
+			for _, a := range t.Attr {
+				switch a.Key {
+				case "name":
+					name = a.Val
+				case "content":
+					content = a.Val
+				}
+			}
+			if (strings.EqualFold(name, "robots") || strings.EqualFold(name, USER_AGENT)) && hasNoIndex(content) {
+				return true
+			}
+		}
+	}
+}
+
+// hasNoIndex checks a comma-separated list of robots directives.
+func hasNoIndex(directives string) bool {
+	for _, d := range strings.Split(directives, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "noindex" || d == "none" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package crawler
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIsNoIndex(t *testing.T) {
+	cases := []struct {
+		header   string
+		content  string
+		expected bool
+	}{
+		{"", "<html><body>Text</body></html>", false},
+		{"noindex", "", true},
+		{"NoIndex, nofollow", "", true},
+		{"none", "", true},
+		{"nofollow", "", false},
+		{"googlebot: noindex", "", true},
+		{"otherbot: noindex", "", false},
+		{"noindex, unavailable_after: 25 Jun 2010", "", true},
+		{"unavailable_after: 25 Jun 2010, noindex", "", true},
+		{"max-snippet: 20", "", false},
+		{"", `<html><head><meta name="robots" content="noindex"></head></html>`, true},
+		{"", `<html><head><meta name="ROBOTS" content="nofollow, NONE"></head></html>`, true},
+		{"", `<html><head><meta name="googlebot" content="noindex"></head></html>`, true},
+		{"", `<html><head><meta name="otherbot" content="noindex"></head></html>`, false},
+		{"", `<html><head><meta name="robots" content="nofollow"></head></html>`, false},
+		// Only the head is checked
+		{"", `<html><body><meta name="robots" content="noindex"></body></html>`, false},
+	}
+	for _, c := range cases {
+		header := http.Header{}
+		if c.header != "" {
+			header.Set("X-Robots-Tag", c.header)
+		}
+		if IsNoIndex(c.content, header) != c.expected {
+			t.Errorf("Expected %v for header %q and content %q", c.expected, c.header, c.content)
+		}
+	}
+}
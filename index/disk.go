@@ -0,0 +1,842 @@
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.roman.zone/crawl/analysis"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Number of documents kept in memory before they are written into a new
+	// segment
+	FLUSH_DOCUMENTS = 1000
+	// Number of adjacent segments that are merged together
+	MERGE_FACTOR = 8
+	// Segments are compacted when this share of their documents is deleted
+	COMPACT_DELETED_RATIO = 0.3
+
+	MANIFEST_FILE     = "segments.json"
+	SEGMENT_EXTENSION = ".seg"
+)
+
+// manifest lists segments that make up an index.
+type manifest struct {
+	// Segment file names in the order of document IDs
+	Segments    []string `json:"segments"`
+	NextSegment int      `json:"next_segment"`
+	NextID      DocID    `json:"next_id"`
+	// Documents that were deleted from segments, but haven't been removed
+	// from the files yet
+	Deleted []DocID `json:"deleted,omitempty"`
+	// Fetch times of documents in segments that were updated after the
+	// segments were written
+	FetchTimes map[DocID]time.Time `json:"fetch_times,omitempty"`
+	// Name of the analyzer in analysis.Analyzers. Indexes created before it
+	// was stored use the default one.
+	Analyzer string `json:"analyzer,omitempty"`
+}
+
+// diskIndex stores an index in a directory as a set of immutable segments.
+//
+// New documents are kept in memory until there are enough of them, and then
+// written into a new segment. Segments are memory-mapped when the index is
+// opened. Since document IDs only grow, each segment covers a range of IDs
+// that comes after the ranges of all the previous segments. Deleting a
+// document from a segment only marks it as deleted.
+//
+// Small segments are merged together in the background, and deleted documents
+// are dropped while merging. Segments with many deleted documents are also
+// compacted on their own.
+//
+// Every change is recorded in a write-ahead log before it's applied, and the
+// log is replayed when the index is opened. The log is cleared once buffered
+// documents are written into a segment. Segments and the manifest are written
+// atomically, so the index is never left half-written.
+type diskIndex struct {
+	dir string
+	// Name of the analyzer and the analyzer itself
+	analyzerName string
+	analyzer     analysis.Analyzer
+	// Documents that haven't been written into a segment yet
+	buffer   *memoryIndex
+	log      *writeAheadLog
+	segments []*segment
+	// Documents in segments that were deleted
+	deleted map[DocID]bool
+	// New fetch times of documents in segments. Pages that are crawled again
+	// without changes only get a new fetch time, and segments can't be
+	// modified, so it's kept here until the segments are merged.
+	fetchTimes map[DocID]time.Time
+	// IDs of all documents in the index
+	ids         map[url.URL]DocID
+	nextSegment int
+	// Cached statistics, nil when the index has been modified since they were
+	// computed
+	stats    *Stats
+	modified bool
+	closed   bool
+	mutex    sync.Mutex
+
+	flushDocuments int
+	mergeFactor    int
+	// Merge requests for the merging goroutine
+	merges chan struct{}
+	// Closed when the merging goroutine stops
+	mergeDone chan struct{}
+}
+
+// NewDiskIndex opens an index stored in a directory. If the directory doesn't
+// exist, a new empty index is created in it.
+//
+// Analyzer is the name of an analyzer in analysis.Analyzers. The index keeps
+// the analyzer it was created with, so the name can be empty when an existing
+// index is opened, and it's an error if it's different. New indexes use
+// analysis.DEFAULT_ANALYZER if the name is empty.
+func NewDiskIndex(dir string, analyzer string) (Index, error) {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		err = os.MkdirAll(dir, 0755)
+	} else if err == nil && !info.IsDir() {
+		err = ErrNotDirectory
+		if strings.HasSuffix(dir, ".csv") {
+			// Indexes used to be stored in CSV files
+			err = ErrOldFormat
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	m, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Manifest is written right away if it doesn't have the analyzer yet
+	saved := m.Analyzer != ""
+	if !saved && len(m.Segments) == 0 && m.NextID == 0 {
+		// New index
+		m.Analyzer = analyzer
+	}
+	if m.Analyzer == "" {
+		m.Analyzer = analysis.DEFAULT_ANALYZER
+	}
+	if analyzer != "" && analyzer != m.Analyzer {
+		return nil, fmt.Errorf("index uses analyzer %q, not %q", m.Analyzer, analyzer)
+	}
+	a, ok := analysis.Analyzers[m.Analyzer]
+	if !ok {
+		return nil, fmt.Errorf("unknown analyzer %q", m.Analyzer)
+	}
+
+	i := &diskIndex{
+		dir:            dir,
+		analyzerName:   m.Analyzer,
+		analyzer:       a,
+		buffer:         newMemoryIndex(a),
+		deleted:        make(map[DocID]bool, len(m.Deleted)),
+		fetchTimes:     make(map[DocID]time.Time, len(m.FetchTimes)),
+		ids:            make(map[url.URL]DocID),
+		nextSegment:    m.NextSegment,
+		flushDocuments: FLUSH_DOCUMENTS,
+		mergeFactor:    MERGE_FACTOR,
+		merges:         make(chan struct{}, 1),
+		mergeDone:      make(chan struct{}),
+	}
+	i.buffer.documents.nextID = m.NextID
+	for _, id := range m.Deleted {
+		i.deleted[id] = true
+	}
+	for id, t := range m.FetchTimes {
+		i.fetchTimes[id] = t
+	}
+	for _, name := range m.Segments {
+		var s *segment
+		s, err = openSegment(filepath.Join(dir, name), name)
+		if err == nil {
+			err = i.loadSegment(s)
+		}
+		if err != nil {
+			err = fmt.Errorf("can't open segment %s: %w", name, err)
+			break
+		}
+	}
+	if err == nil {
+		err = i.removeUnusedFiles()
+	}
+	if err == nil && !saved {
+		err = i.writeManifest()
+	}
+	if err == nil {
+		i.log, err = openLog(filepath.Join(dir, LOG_FILE), i.replay)
+	}
+	if err != nil {
+		i.closeSegments()
+		return nil, err
+	}
+	go i.mergeSegments()
+	return i, nil
+}
+
+func readManifest(dir string) (manifest, error) {
+	var m manifest
+	data, err := os.ReadFile(filepath.Join(dir, MANIFEST_FILE))
+	if os.IsNotExist(err) {
+		return m, nil
+	} else if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// writeManifest saves the current list of segments. Caller must hold the
+// mutex.
+func (i *diskIndex) writeManifest() error {
+	m := manifest{
+		Segments:    make([]string, len(i.segments)),
+		NextSegment: i.nextSegment,
+		NextID:      i.buffer.documents.nextID,
+		Analyzer:    i.analyzerName,
+	}
+	for n, s := range i.segments {
+		m.Segments[n] = s.name
+	}
+	for id := range i.deleted {
+		m.Deleted = append(m.Deleted, id)
+	}
+	sort.Slice(m.Deleted, func(a, b int) bool { return m.Deleted[a] < m.Deleted[b] })
+	if len(i.fetchTimes) > 0 {
+		m.FetchTimes = i.fetchTimes
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(i.dir, MANIFEST_FILE), data)
+}
+
+// loadSegment adds an opened segment after all the other ones.
+func (i *diskIndex) loadSegment(s *segment) error {
+	docs, err := s.documents()
+	if err != nil {
+		s.close()
+		return err
+	}
+	for _, doc := range docs {
+		if !i.deleted[doc.ID] {
+			i.ids[doc.URL] = doc.ID
+		}
+	}
+	i.segments = append(i.segments, s)
+	return nil
+}
+
+// removeUnusedFiles deletes segment files that are not in the manifest and
+// temporary files. They can be left behind if the program crashed while
+// writing a segment.
+func (i *diskIndex) removeUnusedFiles() error {
+	used := make(map[string]bool, len(i.segments))
+	for _, s := range i.segments {
+		used[s.name] = true
+	}
+	entries, err := os.ReadDir(i.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		unused := strings.HasSuffix(e.Name(), SEGMENT_EXTENSION) && !used[e.Name()]
+		if unused || strings.Contains(e.Name(), ".tmp") {
+			if err := os.Remove(filepath.Join(i.dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newSegmentName returns a file name for the next segment. Caller must hold
+// the mutex.
+func (i *diskIndex) newSegmentName() string {
+	name := fmt.Sprintf("%08d%s", i.nextSegment, SEGMENT_EXTENSION)
+	i.nextSegment++
+	return name
+}
+
+// replay applies a change from the write-ahead log.
+func (i *diskIndex) replay(r logRecord) {
+	switch r.op {
+	case logAdd:
+		i.add(analyzePage(r.page, i.analyzer))
+	case logDelete:
+		i.delete(r.page.URL)
+	case logFetch:
+		if id, ok := i.ids[r.page.URL]; ok {
+			i.setFetchTime(id, r.page.FetchTime)
+		}
+	}
+}
+
+func (i *diskIndex) Add(page Page) error {
+	return i.AddBatch([]Page{page})
+}
+
+// AddBatch writes all pages into the log at once, so they only need to be
+// synced once.
+func (i *diskIndex) AddBatch(pages []Page) error {
+	analyzed := make([]analyzedPage, len(pages))
+	records := make([]logRecord, len(pages))
+	for n, page := range pages {
+		analyzed[n] = analyzePage(page, i.analyzer)
+		records[n] = logRecord{op: logAdd, page: page}
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return ErrClosed
+	}
+	if err := i.log.append(records...); err != nil {
+		return err
+	}
+	for _, a := range analyzed {
+		i.add(a)
+	}
+	return i.flushIfFull()
+}
+
+func (i *diskIndex) Update(page Page) error {
+	a := analyzePage(page, i.analyzer)
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return ErrClosed
+	}
+	if id, ok := i.ids[page.URL]; ok {
+		if doc, ok := i.document(id); ok && doc.Hash == pageHash(page) {
+			if doc.FetchTime.Equal(page.FetchTime) {
+				return nil
+			}
+			if err := i.log.append(logRecord{op: logFetch, page: page}); err != nil {
+				return err
+			}
+			i.setFetchTime(id, page.FetchTime)
+			return nil
+		}
+	}
+	if err := i.log.append(logRecord{op: logAdd, page: page}); err != nil {
+		return err
+	}
+	i.add(a)
+	return i.flushIfFull()
+}
+
+// flushIfFull flushes the buffer if it has enough documents. Caller must hold
+// the mutex.
+func (i *diskIndex) flushIfFull() error {
+	if i.buffer.documents.len() >= i.flushDocuments {
+		return i.flush()
+	}
+	return nil
+}
+
+// setFetchTime changes the fetch time of a document. Caller must hold the
+// mutex.
+func (i *diskIndex) setFetchTime(id DocID, t time.Time) {
+	if doc, ok := i.buffer.documents.get(id); ok {
+		doc.FetchTime = t
+		i.buffer.documents.put(doc)
+	} else {
+		i.fetchTimes[id] = t
+	}
+	i.setModified()
+}
+
+// add puts a page into the buffer. Caller must hold the mutex.
+func (i *diskIndex) add(a analyzedPage) {
+	i.delete(a.page.URL)
+	doc := i.buffer.documents.newDocument(a.page, a.length())
+	i.buffer.addDocument(doc, a.postings(doc.ID))
+	i.buffer.documents.putText(doc.ID, a.text)
+	i.ids[doc.URL] = doc.ID
+	i.setModified()
+}
+
+func (i *diskIndex) Delete(pageURL url.URL) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return ErrClosed
+	}
+	if _, ok := i.ids[pageURL]; !ok {
+		return nil
+	}
+	if err := i.log.append(logRecord{op: logDelete, page: Page{URL: pageURL}}); err != nil {
+		return err
+	}
+	i.delete(pageURL)
+	return nil
+}
+
+// delete removes a page from the buffer or marks it as deleted if it's in one
+// of the segments. Caller must hold the mutex.
+func (i *diskIndex) delete(pageURL url.URL) {
+	id, ok := i.ids[pageURL]
+	if !ok {
+		return
+	}
+	delete(i.ids, pageURL)
+	if _, ok := i.buffer.documents.get(id); ok {
+		i.buffer.delete(pageURL)
+	} else {
+		i.deleted[id] = true
+		delete(i.fetchTimes, id)
+		// Segment might need to be compacted now
+		i.requestMerge()
+	}
+	i.setModified()
+}
+
+// requestMerge wakes up the merging goroutine. If it's busy, it will check
+// the segments again anyway. Caller must hold the mutex.
+func (i *diskIndex) requestMerge() {
+	if i.closed {
+		return
+	}
+	select {
+	case i.merges <- struct{}{}:
+	default:
+	}
+}
+
+// setModified is called after any change. Caller must hold the mutex.
+func (i *diskIndex) setModified() {
+	i.modified = true
+	i.stats = nil
+}
+
+// flush writes buffered documents into a new segment and clears the
+// write-ahead log. Caller must hold the mutex.
+func (i *diskIndex) flush() error {
+	if i.buffer.documents.len() == 0 {
+		if i.modified {
+			// There could still be deletions in the log
+			if err := i.writeManifest(); err != nil {
+				return err
+			}
+			i.modified = false
+		}
+		return i.log.reset()
+	}
+	ids := i.buffer.allDocuments()
+	docs := make([]Document, len(ids))
+	for n, id := range ids {
+		docs[n], _ = i.buffer.documents.get(id)
+	}
+	name := i.newSegmentName()
+	path := filepath.Join(i.dir, name)
+	if err := writeSegment(path, docs, i.buffer.documents.texts, i.buffer.mapping); err != nil {
+		return err
+	}
+	s, err := openSegment(path, name)
+	if err != nil {
+		return err
+	}
+	i.segments = append(i.segments, s)
+	nextID := i.buffer.documents.nextID
+	i.buffer = newMemoryIndex(i.analyzer)
+	i.buffer.documents.nextID = nextID
+	if err := i.writeManifest(); err != nil {
+		return err
+	}
+	i.modified = false
+	if err := i.log.reset(); err != nil {
+		return err
+	}
+	i.requestMerge()
+	return nil
+}
+
+// mergeSegments runs in the background and merges segments when requested.
+func (i *diskIndex) mergeSegments() {
+	defer close(i.mergeDone)
+	for range i.merges {
+		for {
+			merged, err := i.merge()
+			if err != nil {
+				log.Printf("Failed to merge index segments: %v", err)
+			}
+			if !merged || err != nil {
+				break
+			}
+		}
+	}
+}
+
+// merge replaces several adjacent segments with a single one, if there are
+// enough segments, or compacts a segment with many deleted documents.
+// Documents are read from the old segments without holding the mutex, which
+// is fine since segments don't change and only this function removes them.
+func (i *diskIndex) merge() (bool, error) {
+	i.mutex.Lock()
+	start, count := pickMerge(i.segments, i.mergeFactor)
+	if start < 0 {
+		start, count = i.pickCompaction()
+	}
+	if start < 0 {
+		i.mutex.Unlock()
+		return false, nil
+	}
+	old := append([]*segment(nil), i.segments[start:start+count]...)
+	deleted := make(map[DocID]bool, len(i.deleted))
+	for id := range i.deleted {
+		deleted[id] = true
+	}
+	fetchTimes := make(map[DocID]time.Time, len(i.fetchTimes))
+	for id, t := range i.fetchTimes {
+		fetchTimes[id] = t
+	}
+	name := i.newSegmentName()
+	i.mutex.Unlock()
+
+	docs, texts, postings, err := mergeContents(old, deleted, fetchTimes)
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(i.dir, name)
+	var merged []*segment
+	if len(docs) > 0 {
+		if err := writeSegment(path, docs, texts, postings); err != nil {
+			return false, err
+		}
+		s, err := openSegment(path, name)
+		if err != nil {
+			return false, err
+		}
+		merged = append(merged, s)
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	// Flushing only appends segments, so the old ones are still in the same
+	// place.
+	i.segments = append(i.segments[:start], append(merged, i.segments[start+len(old):]...)...)
+	for _, s := range old {
+		for _, id := range s.allIDs() {
+			if deleted[id] {
+				delete(i.deleted, id)
+			}
+			// Fetch time could have changed again while merging
+			if t, ok := fetchTimes[id]; ok && t.Equal(i.fetchTimes[id]) {
+				delete(i.fetchTimes, id)
+			}
+		}
+	}
+	i.setModified()
+	if err := i.writeManifest(); err != nil {
+		return false, err
+	}
+	for _, s := range old {
+		if err := s.close(); err != nil {
+			return false, err
+		}
+		if err := os.Remove(filepath.Join(i.dir, s.name)); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// pickMerge finds adjacent segments that should be merged and returns index
+// of the first one and their number, or -1 if there are not enough segments.
+// Segments with the smallest total number of documents are chosen, so that
+// large segments are not rewritten too often.
+func pickMerge(segments []*segment, factor int) (int, int) {
+	if factor < 2 || len(segments) < factor {
+		return -1, 0
+	}
+	best, bestSize := -1, 0
+	for start := 0; start+factor <= len(segments); start++ {
+		size := 0
+		for _, s := range segments[start : start+factor] {
+			size += s.docCount
+		}
+		if best < 0 || size < bestSize {
+			best, bestSize = start, size
+		}
+	}
+	return best, factor
+}
+
+// pickCompaction finds a segment where the share of deleted documents is at
+// least COMPACT_DELETED_RATIO. Caller must hold the mutex.
+func (i *diskIndex) pickCompaction() (int, int) {
+	if len(i.deleted) == 0 {
+		return -1, 0
+	}
+	deleted := make([]int, len(i.segments))
+	for id := range i.deleted {
+		n := sort.Search(len(i.segments), func(n int) bool { return i.segments[n].maxID() >= id })
+		if n < len(i.segments) {
+			deleted[n]++
+		}
+	}
+	for n, s := range i.segments {
+		if float64(deleted[n]) >= COMPACT_DELETED_RATIO*float64(s.docCount) {
+			return n, 1
+		}
+	}
+	return -1, 0
+}
+
+// mergeContents reads documents, their texts and postings of segments,
+// skipping deleted documents and applying new fetch times.
+func mergeContents(segments []*segment, deleted map[DocID]bool, fetchTimes map[DocID]time.Time) ([]Document, map[DocID][]byte, map[Keyword][]Posting, error) {
+	docs := make([]Document, 0)
+	texts := make(map[DocID][]byte)
+	postings := make(map[Keyword][]Posting)
+	for _, s := range segments {
+		segmentDocs, err := s.documents()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, doc := range segmentDocs {
+			if !deleted[doc.ID] {
+				if t, ok := fetchTimes[doc.ID]; ok {
+					doc.FetchTime = t
+				}
+				docs = append(docs, doc)
+				texts[doc.ID], _ = s.compressedText(doc.ID)
+			}
+		}
+		for _, keyword := range s.allTerms() {
+			for _, p := range s.postings(keyword) {
+				if !deleted[p.DocID] {
+					postings[keyword] = append(postings[keyword], p)
+				}
+			}
+		}
+	}
+	return docs, texts, postings, nil
+}
+
+func (i *diskIndex) Search(q Query) ([]Hit, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return nil, ErrClosed
+	}
+	return search(i, q), nil
+}
+
+// postings implements postingsSource. Caller must hold the mutex.
+func (i *diskIndex) postings(keyword Keyword) []Posting {
+	result := make([]Posting, 0)
+	for _, s := range i.segments {
+		for _, p := range s.postings(keyword) {
+			if !i.deleted[p.DocID] {
+				result = append(result, p)
+			}
+		}
+	}
+	return append(result, i.buffer.postings(keyword)...)
+}
+
+func (i *diskIndex) document(id DocID) (Document, bool) {
+	if doc, ok := i.buffer.document(id); ok {
+		return doc, true
+	}
+	if i.deleted[id] {
+		return Document{}, false
+	}
+	n := sort.Search(len(i.segments), func(n int) bool { return i.segments[n].maxID() >= id })
+	if n == len(i.segments) {
+		return Document{}, false
+	}
+	doc, ok := i.segments[n].document(id)
+	if t, updated := i.fetchTimes[id]; ok && updated {
+		doc.FetchTime = t
+	}
+	return doc, ok
+}
+
+func (i *diskIndex) allDocuments() []DocID {
+	ids := make([]DocID, 0, len(i.ids))
+	for _, s := range i.segments {
+		for _, id := range s.allIDs() {
+			if !i.deleted[id] {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return append(ids, i.buffer.allDocuments()...)
+}
+
+// keywords returns keywords of all segments and the buffer. Keywords of
+// deleted documents can be included until the segments are merged.
+func (i *diskIndex) keywords(prefix Keyword) []Keyword {
+	keywords := i.buffer.keywords(prefix)
+	for _, s := range i.segments {
+		keywords = append(keywords, s.keywords(prefix)...)
+	}
+	sort.Slice(keywords, func(a, b int) bool { return keywords[a] < keywords[b] })
+	unique := keywords[:0]
+	for n, k := range keywords {
+		if n == 0 || k != keywords[n-1] {
+			unique = append(unique, k)
+		}
+	}
+	return unique
+}
+
+// Vocabulary counts documents in segments from their term tables, so deleted
+// documents are counted until the segments are merged.
+func (i *diskIndex) Vocabulary(prefix Keyword) ([]KeywordCount, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return nil, ErrClosed
+	}
+	documents := make(map[Keyword]int)
+	for _, c := range i.buffer.keywordCounts(prefix) {
+		documents[c.Keyword] += c.Documents
+	}
+	for _, s := range i.segments {
+		for _, c := range s.keywordCounts(prefix) {
+			if field, _ := SplitFieldKeyword(c.Keyword); field == FIELD_BODY {
+				documents[c.Keyword] += c.Documents
+			}
+		}
+	}
+	counts := make([]KeywordCount, 0, len(documents))
+	for k, n := range documents {
+		counts = append(counts, KeywordCount{Keyword: k, Documents: n})
+	}
+	sort.Slice(counts, func(a, b int) bool { return counts[a].Keyword < counts[b].Keyword })
+	return counts, nil
+}
+
+func (i *diskIndex) DocumentFrequency(keyword Keyword) (int, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return 0, ErrClosed
+	}
+	return len(i.postings(keyword)), nil
+}
+
+func (i *diskIndex) Document(pageURL url.URL) (Document, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return Document{}, ErrClosed
+	}
+	id, ok := i.ids[pageURL]
+	if !ok {
+		return Document{}, ErrNotFound
+	}
+	doc, ok := i.document(id)
+	if !ok {
+		return Document{}, ErrNotFound
+	}
+	return doc, nil
+}
+
+func (i *diskIndex) Text(id DocID) (string, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return "", ErrClosed
+	}
+	text, ok := i.buffer.documents.text(id)
+	if !ok && !i.deleted[id] {
+		n := sort.Search(len(i.segments), func(n int) bool { return i.segments[n].maxID() >= id })
+		if n < len(i.segments) {
+			text, ok = i.segments[n].compressedText(id)
+		}
+	}
+	if !ok {
+		return "", ErrNotFound
+	}
+	return decompressText(text)
+}
+
+func (i *diskIndex) Analyzer() analysis.Analyzer {
+	return i.analyzer
+}
+
+// Stats goes through the whole index, so the result is cached until the index
+// is modified.
+func (i *diskIndex) Stats() Stats {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.stats != nil {
+		return *i.stats
+	}
+	stats := Stats{Documents: len(i.ids)}
+	for _, id := range i.allDocuments() {
+		if doc, ok := i.document(id); ok {
+			stats.TotalLength += doc.Length
+		}
+	}
+	keywords := make(map[Keyword]bool)
+	for _, s := range i.segments {
+		for _, keyword := range s.allTerms() {
+			keywords[keyword] = true
+		}
+	}
+	for keyword := range i.buffer.mapping {
+		keywords[keyword] = true
+	}
+	for keyword := range keywords {
+		if count := len(i.postings(keyword)); count > 0 {
+			stats.Keywords++
+			stats.Postings += count
+		}
+	}
+	i.stats = &stats
+	return stats
+}
+
+// Close waits for a merge in progress to finish and writes buffered documents
+// into a segment.
+func (i *diskIndex) Close() error {
+	i.mutex.Lock()
+	if i.closed {
+		i.mutex.Unlock()
+		return nil
+	}
+	i.closed = true
+	i.mutex.Unlock()
+
+	close(i.merges)
+	<-i.mergeDone
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	err := i.flush()
+	if closeErr := i.log.close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := i.closeSegments(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// closeSegments releases all segments. Caller must hold the mutex.
+func (i *diskIndex) closeSegments() error {
+	var err error
+	for _, s := range i.segments {
+		if closeErr := s.close(); err == nil {
+			err = closeErr
+		}
+	}
+	i.segments = nil
+	return err
+}
@@ -0,0 +1,153 @@
+package index
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DocID identifies a document within an index.
+type DocID uint32
+
+// Document holds information about an indexed page. It's stored separately
+// from postings which only reference documents by their IDs.
+type Document struct {
+	ID          DocID
+	URL         url.URL
+	Title       string
+	Language    string
+	ContentType string
+	FetchTime   time.Time
+	// Number of keywords in the document
+	Length int
+	// SHA-256 of all fields and attributes of the page except the fetch time
+	Hash     string
+	Metadata map[string]string
+}
+
+// documentStore maps document IDs to documents. It's not safe for concurrent
+// use.
+type documentStore struct {
+	documents map[DocID]Document
+	// Compressed texts of documents
+	texts  map[DocID][]byte
+	ids    map[url.URL]DocID
+	nextID DocID
+	// Sum of lengths of all stored documents
+	totalLength int
+}
+
+func newDocumentStore() *documentStore {
+	return &documentStore{
+		documents: make(map[DocID]Document),
+		texts:     make(map[DocID][]byte),
+		ids:       make(map[url.URL]DocID),
+	}
+}
+
+// newDocument creates a document for a page and assigns a new ID to it. The
+// document is not stored.
+func (s *documentStore) newDocument(page Page, length int) Document {
+	doc := Document{
+		ID:          s.nextID,
+		URL:         page.URL,
+		Title:       page.Title,
+		Language:    page.Language,
+		ContentType: strings.ToLower(page.ContentType),
+		FetchTime:   page.FetchTime,
+		Length:      length,
+		Hash:        pageHash(page),
+		Metadata:    page.Metadata,
+	}
+	s.nextID++
+	return doc
+}
+
+// pageHash returns a hash of everything that is stored about a page except
+// the fetch time, so that a page with any changes is reindexed. Encoding of
+// log records is used, as it includes all of it. The fetch time changes every
+// time a page is crawled and can be updated without reindexing.
+func pageHash(page Page) string {
+	page.FetchTime = time.Time{}
+	hash := sha256.Sum256(encodeLogRecord(logRecord{op: logAdd, page: page}))
+	return hex.EncodeToString(hash[:])
+}
+
+// put stores a document or updates an existing one with the same ID.
+func (s *documentStore) put(doc Document) {
+	if old, ok := s.documents[doc.ID]; ok {
+		s.totalLength -= old.Length
+	}
+	s.totalLength += doc.Length
+	s.documents[doc.ID] = doc
+	s.ids[doc.URL] = doc.ID
+	if doc.ID >= s.nextID {
+		s.nextID = doc.ID + 1
+	}
+}
+
+func (s *documentStore) get(id DocID) (Document, bool) {
+	doc, ok := s.documents[id]
+	return doc, ok
+}
+
+// putText stores compressed text of a document.
+func (s *documentStore) putText(id DocID, text []byte) {
+	s.texts[id] = text
+}
+
+func (s *documentStore) text(id DocID) ([]byte, bool) {
+	text, ok := s.texts[id]
+	return text, ok
+}
+
+func (s *documentStore) lookup(pageURL url.URL) (Document, bool) {
+	id, ok := s.ids[pageURL]
+	if !ok {
+		return Document{}, false
+	}
+	return s.get(id)
+}
+
+func (s *documentStore) delete(id DocID) {
+	if doc, ok := s.documents[id]; ok {
+		s.totalLength -= doc.Length
+		delete(s.ids, doc.URL)
+		delete(s.documents, id)
+		delete(s.texts, id)
+	}
+}
+
+// allIDs returns IDs of all documents in ascending order.
+func (s *documentStore) allIDs() []DocID {
+	ids := make([]DocID, 0, len(s.documents))
+	for id := range s.documents {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (s *documentStore) len() int {
+	return len(s.documents)
+}
+
+// compressText compresses text of a document for storage.
+func compressText(text string) []byte {
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.DefaultCompression) // Only fails for invalid levels
+	w.Write([]byte(text))
+	w.Close()
+	return buf.Bytes()
+}
+
+func decompressText(data []byte) (string, error) {
+	text, err := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	return string(text), err
+}
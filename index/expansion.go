@@ -0,0 +1,197 @@
+package index
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// Maximum number of keywords that a prefix, wildcard or fuzzy query is
+	// expanded to
+	MAX_EXPANSIONS = 64
+	// Maximum edit distance of fuzzy queries, larger distances match too much
+	MAX_EDIT_DISTANCE = 2
+	// Number of first characters that keywords need to share with a fuzzy
+	// keyword. Typos are rarely made in the first character and this avoids
+	// going through the whole vocabulary.
+	FUZZY_PREFIX_LENGTH = 1
+
+	WILDCARD_ANY = '*'
+	WILDCARD_ONE = '?'
+)
+
+// Prefix matches documents with keywords that start with a prefix. If Field is
+// empty, keywords in all fields are matched.
+type Prefix struct {
+	Field  Field
+	Prefix Keyword
+}
+
+func (q Prefix) execute(s postingsSource) []match {
+	return expand(s, q.Field, string(q.Prefix), func(Keyword) int { return 0 })
+}
+
+// Wildcard matches documents with keywords that match a pattern, where
+// WILDCARD_ANY stands for any number of characters and WILDCARD_ONE for
+// exactly one. If Field is empty, keywords in all fields are matched.
+type Wildcard struct {
+	Field   Field
+	Pattern string
+}
+
+func (q Wildcard) execute(s postingsSource) []match {
+	prefix := q.Pattern
+	if i := strings.IndexAny(q.Pattern, string([]rune{WILDCARD_ANY, WILDCARD_ONE})); i >= 0 {
+		prefix = q.Pattern[:i]
+	}
+	return expand(s, q.Field, prefix, func(k Keyword) int {
+		if matchWildcard([]rune(q.Pattern), []rune(string(k))) {
+			return 0
+		}
+		return -1
+	})
+}
+
+// Fuzzy matches documents with keywords that are within an edit distance from
+// a keyword. Closer keywords are preferred when there are more than
+// MAX_EXPANSIONS of them. If Field is empty, keywords in all fields are
+// matched.
+type Fuzzy struct {
+	Field    Field
+	Keyword  Keyword
+	Distance int
+}
+
+func (q Fuzzy) execute(s postingsSource) []match {
+	distance := q.Distance
+	if distance > MAX_EDIT_DISTANCE {
+		distance = MAX_EDIT_DISTANCE
+	}
+	prefix := string(q.Keyword)
+	for i := range prefix {
+		if utf8.RuneCountInString(prefix[:i]) == FUZZY_PREFIX_LENGTH {
+			prefix = prefix[:i]
+			break
+		}
+	}
+	return expand(s, q.Field, prefix, func(k Keyword) int {
+		if d := analysis.EditDistance(string(q.Keyword), string(k), distance); d <= distance {
+			return d
+		}
+		return -1
+	})
+}
+
+// expand finds keywords that start with a prefix and have a non-negative
+// distance, and matches documents with any of them. When there are more than
+// MAX_EXPANSIONS of them, keywords with smaller distances go first, and then
+// the ones that are in more documents.
+func expand(s postingsSource, field Field, prefix string, distance func(Keyword) int) []match {
+	fields := Fields
+	if field != "" {
+		fields = []Field{field}
+	}
+	// Keywords in every field that have the same base keyword
+	found := make(map[Keyword][]Keyword)
+	distances := make(map[Keyword]int)
+	for _, f := range fields {
+		for _, k := range s.keywords(FieldKeyword(f, Keyword(prefix))) {
+			// Listing keywords of the body also lists keywords of other
+			// fields, which have a prefix.
+			kf, base := SplitFieldKeyword(k)
+			if kf != f {
+				continue
+			}
+			if _, ok := distances[base]; !ok {
+				d := distance(base)
+				if d < 0 {
+					continue
+				}
+				distances[base] = d
+			}
+			found[base] = append(found[base], k)
+		}
+	}
+	bases := make([]Keyword, 0, len(found))
+	for base := range found {
+		bases = append(bases, base)
+	}
+	if len(bases) > MAX_EXPANSIONS {
+		// Document frequency in all fields, only needed to choose keywords
+		frequencies := make(map[Keyword]int, len(bases))
+		for base, keywords := range found {
+			for _, k := range keywords {
+				frequencies[base] += len(s.postings(k))
+			}
+		}
+		sort.Slice(bases, func(i, j int) bool {
+			a, b := bases[i], bases[j]
+			if distances[a] != distances[b] {
+				return distances[a] < distances[b]
+			}
+			if frequencies[a] != frequencies[b] {
+				return frequencies[a] > frequencies[b]
+			}
+			return a < b
+		})
+		bases = bases[:MAX_EXPANSIONS]
+	}
+
+	result := make([]match, 0)
+	for _, base := range bases {
+		for _, k := range found[base] {
+			result = union(result, keywordMatches(s, k))
+		}
+	}
+	return result
+}
+
+// keywordsWithPrefix returns keywords that start with a prefix from a sorted
+// list of keywords.
+func keywordsWithPrefix(count int, keywordAt func(n int) Keyword, prefix Keyword) []Keyword {
+	keywords := make([]Keyword, 0)
+	n := sort.Search(count, func(n int) bool { return keywordAt(n) >= prefix })
+	for ; n < count; n++ {
+		k := keywordAt(n)
+		if !strings.HasPrefix(string(k), string(prefix)) {
+			break
+		}
+		keywords = append(keywords, k)
+	}
+	return keywords
+}
+
+// foldPattern converts a pattern to the form of keywords. Patterns can't be
+// split into terms like other text, because that would remove wildcards.
+func foldPattern(pattern string) string {
+	return analysis.FoldAccents(analysis.FoldCase(pattern))
+}
+
+// matchWildcard checks if a keyword matches a pattern with wildcards.
+func matchWildcard(pattern, keyword []rune) bool {
+	p, k := 0, 0
+	// Position of the last WILDCARD_ANY and the part of the keyword that
+	// it's matched to, which is extended if the rest doesn't match.
+	star, starK := -1, 0
+	for k < len(keyword) {
+		switch {
+		case p < len(pattern) && pattern[p] == WILDCARD_ANY:
+			star, starK = p, k
+			p++
+		case p < len(pattern) && (pattern[p] == WILDCARD_ONE || pattern[p] == keyword[k]):
+			p++
+			k++
+		case star >= 0:
+			starK++
+			p, k = star+1, starK
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == WILDCARD_ANY {
+		p++
+	}
+	return p == len(pattern)
+}
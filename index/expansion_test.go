@@ -0,0 +1,76 @@
+package index
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestExpansion(t *testing.T) {
+	idx, err := NewDiskIndex(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	i := idx.(*diskIndex)
+	i.flushDocuments = 3
+	contents := []string{"crawler", "crawling", "crawled", "crawl", "craw", "scrawl", "brawler"}
+	for n := 0; n < MAX_EXPANSIONS+10; n++ {
+		contents = append(contents, fmt.Sprintf("prefix%03d", n))
+	}
+	// Frequent keyword that is the last one in the alphabet
+	contents = append(contents, "prefix999", "prefix999")
+	for n, content := range contents {
+		page := Page{URL: url.URL{Scheme: "https", Host: fmt.Sprintf("%d.example.com", n)}, Content: content}
+		if err := i.Add(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		query    Query
+		expected int
+	}{
+		{Prefix{Prefix: "crawl"}, 4},
+		{Prefix{Field: FIELD_TITLE, Prefix: "crawl"}, 0},
+		{Wildcard{Pattern: "*rawl*"}, 6},
+		{Wildcard{Pattern: "crawl??"}, 2},
+		{Fuzzy{Keyword: "crawlr", Distance: 1}, 2},
+		{Fuzzy{Keyword: "crawlr", Distance: 2}, 4},
+		{Fuzzy{Keyword: "crawlr", Distance: 0}, 0},
+		// Expansion stops at the limit, keeping the frequent keyword
+		{Prefix{Prefix: "prefix"}, MAX_EXPANSIONS + 1},
+		{And{Prefix{Prefix: "prefix"}, Term{"prefix999"}}, 2},
+	}
+	for _, c := range cases {
+		hits, err := i.Search(c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hits) != c.expected {
+			t.Errorf("Expected %d hits for %+v, got %d", c.expected, c.query, len(hits))
+		}
+	}
+}
+
+func TestMatchWildcard(t *testing.T) {
+	cases := []struct {
+		pattern, keyword string
+		expected         bool
+	}{
+		{"crawl*", "crawler", true},
+		{"crawl*", "crawl", true},
+		{"*ler", "crawler", true},
+		{"c*l*r", "crawler", true},
+		{"cr?wler", "crawler", true},
+		{"cr?wler", "crwler", false},
+		{"crawl?", "crawl", false},
+		{"*a*b", "aab", true},
+		{"*a*b", "aaba", false},
+	}
+	for _, c := range cases {
+		if matchWildcard([]rune(c.pattern), []rune(c.keyword)) != c.expected {
+			t.Errorf("Expected %v for %q and %q", c.expected, c.pattern, c.keyword)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package facet counts search results by their attributes, so that they can
+// be narrowed down with filters.
+package facet
+
+import (
+	"go.roman.zone/crawl/index"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Maximum number of values of each facet
+const DEFAULT_SIZE = 10
+
+// DateBucket is a period of time before now. Buckets overlap, a document
+// fetched in the past day is also fetched in the past week.
+type DateBucket struct {
+	Name string
+	Age  time.Duration
+}
+
+var DateBuckets = []DateBucket{
+	{"day", 24 * time.Hour},
+	{"week", 7 * 24 * time.Hour},
+	{"month", 30 * 24 * time.Hour},
+	{"year", 365 * 24 * time.Hour},
+}
+
+// Count is the number of documents with a value of an attribute.
+type Count struct {
+	Value string
+	Count int
+}
+
+// DateCount is the number of documents fetched since a time.
+type DateCount struct {
+	Bucket string
+	Since  time.Time
+	Count  int
+}
+
+type Facets struct {
+	Hosts        []Count
+	Languages    []Count
+	ContentTypes []Count
+	Dates        []DateCount
+}
+
+// Compute counts documents by host, language, content type and the time they
+// were fetched. Only the most common values of each attribute are kept, up to
+// size of them. Empty values aren't counted.
+func Compute(docs []index.Document, now time.Time, size int) Facets {
+	if size <= 0 {
+		size = DEFAULT_SIZE
+	}
+	hosts := make(map[string]int)
+	languages := make(map[string]int)
+	contentTypes := make(map[string]int)
+	dates := make([]DateCount, len(DateBuckets))
+	for n, b := range DateBuckets {
+		dates[n] = DateCount{Bucket: b.Name, Since: now.Add(-b.Age)}
+	}
+	for _, doc := range docs {
+		hosts[strings.ToLower(doc.URL.Hostname())]++
+		languages[doc.Language]++
+		contentTypes[doc.ContentType]++
+		for n := range dates {
+			if !doc.FetchTime.Before(dates[n].Since) {
+				dates[n].Count++
+			}
+		}
+	}
+	return Facets{
+		Hosts:        top(hosts, size),
+		Languages:    top(languages, size),
+		ContentTypes: top(contentTypes, size),
+		Dates:        dates,
+	}
+}
+
+// top returns the most common values, sorted by count and then by value.
+func top(counts map[string]int, size int) []Count {
+	result := make([]Count, 0, len(counts))
+	for value, count := range counts {
+		if value != "" {
+			result = append(result, Count{Value: value, Count: count})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+	if len(result) > size {
+		result = result[:size]
+	}
+	return result
+}
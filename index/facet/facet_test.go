@@ -0,0 +1,34 @@
+package facet
+
+import (
+	"go.roman.zone/crawl/index"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompute(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	docs := []index.Document{
+		{URL: url.URL{Host: "a.com"}, Language: "en", ContentType: "text/html", FetchTime: now.Add(-time.Hour)},
+		{URL: url.URL{Host: "A.com:8080"}, Language: "en", ContentType: "text/html", FetchTime: now.Add(-48 * time.Hour)},
+		{URL: url.URL{Host: "b.com"}, Language: "de", ContentType: "text/plain", FetchTime: now.Add(-40 * 24 * time.Hour)},
+		{URL: url.URL{Host: "c.com"}, ContentType: "text/html", FetchTime: now.Add(-400 * 24 * time.Hour)},
+	}
+	facets := Compute(docs, now, 2)
+	expected := Facets{
+		Hosts:        []Count{{"a.com", 2}, {"b.com", 1}},
+		Languages:    []Count{{"en", 2}, {"de", 1}},
+		ContentTypes: []Count{{"text/html", 3}, {"text/plain", 1}},
+		Dates: []DateCount{
+			{"day", now.Add(-24 * time.Hour), 1},
+			{"week", now.Add(-7 * 24 * time.Hour), 2},
+			{"month", now.Add(-30 * 24 * time.Hour), 2},
+			{"year", now.Add(-365 * 24 * time.Hour), 3},
+		},
+	}
+	if !reflect.DeepEqual(facets, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, facets)
+	}
+}
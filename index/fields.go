@@ -0,0 +1,92 @@
+package index
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"strings"
+)
+
+// Field is a part of a page that is indexed separately, so that queries can
+// be restricted to it and matches in it can be weighted differently.
+type Field string
+
+const (
+	// Text of the whole page
+	FIELD_BODY     Field = "body"
+	FIELD_TITLE    Field = "title"
+	FIELD_HEADINGS Field = "headings"
+	// Words of the host name and path
+	FIELD_URL Field = "url"
+	// Text of links to the page from other pages
+	FIELD_ANCHOR Field = "anchor"
+
+	// Separates field name from a keyword in keywords of fields other than
+	// body. Analysis never produces keywords that contain it.
+	FIELD_SEPARATOR = ":"
+)
+
+var Fields = []Field{FIELD_BODY, FIELD_TITLE, FIELD_HEADINGS, FIELD_URL, FIELD_ANCHOR}
+
+// ParseField returns a field by its name.
+func ParseField(name string) (Field, bool) {
+	for _, f := range Fields {
+		if string(f) == strings.ToLower(name) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// FieldKeyword returns the keyword that stores postings of a keyword in a
+// field, like "title:crawler". Keywords of the body are stored as they are.
+func FieldKeyword(field Field, keyword Keyword) Keyword {
+	if field == FIELD_BODY || field == "" {
+		return keyword
+	}
+	return Keyword(string(field) + FIELD_SEPARATOR + string(keyword))
+}
+
+// SplitFieldKeyword is the reverse of FieldKeyword.
+func SplitFieldKeyword(keyword Keyword) (Field, Keyword) {
+	if i := strings.Index(string(keyword), FIELD_SEPARATOR); i >= 0 {
+		return Field(keyword[:i]), keyword[i+len(FIELD_SEPARATOR):]
+	}
+	return FIELD_BODY, keyword
+}
+
+// analyzedPage is a page split into keywords of every field, along with its
+// compressed text.
+type analyzedPage struct {
+	page   Page
+	fields map[Field][]Keyword
+	text   []byte
+}
+
+func analyzePage(page Page, analyzer analysis.Analyzer) analyzedPage {
+	return analyzedPage{
+		page: page,
+		text: compressText(page.Content),
+		fields: map[Field][]Keyword{
+			FIELD_BODY:     Keywords(analyzer, page.Content),
+			FIELD_TITLE:    Keywords(analyzer, page.Title),
+			FIELD_HEADINGS: Keywords(analyzer, strings.Join(page.Headings, "\n")),
+			FIELD_URL:      Keywords(analyzer, page.URL.Hostname()+" "+page.URL.Path),
+			FIELD_ANCHOR:   Keywords(analyzer, strings.Join(page.Anchors, "\n")),
+		},
+	}
+}
+
+// length is the number of keywords in the body.
+func (a analyzedPage) length() int {
+	return len(a.fields[FIELD_BODY])
+}
+
+// postings returns postings of all fields.
+func (a analyzedPage) postings(id DocID) map[Keyword]Posting {
+	postings := make(map[Keyword]Posting)
+	for field, keywords := range a.fields {
+		for keyword, p := range postingsFor(id, keywords) {
+			postings[FieldKeyword(field, keyword)] = p
+		}
+	}
+	return postings
+}
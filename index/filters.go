@@ -0,0 +1,78 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Language matches documents in a language.
+type Language struct {
+	Code string
+}
+
+func (q Language) execute(s postingsSource) []match {
+	return applyFilters(s, allMatches(s), []filter{q})
+}
+
+func (q Language) accepts(doc Document) bool {
+	return strings.EqualFold(doc.Language, q.Code)
+}
+
+// ContentType matches documents with a MIME type like "text/html". A type
+// without a subtype like "text" matches all of its subtypes.
+type ContentType struct {
+	Type string
+}
+
+func (q ContentType) execute(s postingsSource) []match {
+	return applyFilters(s, allMatches(s), []filter{q})
+}
+
+func (q ContentType) accepts(doc Document) bool {
+	t := strings.ToLower(q.Type)
+	if !strings.Contains(t, "/") {
+		return strings.HasPrefix(doc.ContentType, t+"/")
+	}
+	return doc.ContentType == t
+}
+
+// FetchedBetween matches documents fetched at or after From and before To.
+// Zero times leave the range open.
+type FetchedBetween struct {
+	From, To time.Time
+}
+
+func (q FetchedBetween) execute(s postingsSource) []match {
+	return applyFilters(s, allMatches(s), []filter{q})
+}
+
+func (q FetchedBetween) accepts(doc Document) bool {
+	if !q.From.IsZero() && doc.FetchTime.Before(q.From) {
+		return false
+	}
+	return q.To.IsZero() || doc.FetchTime.Before(q.To)
+}
+
+// ParseTime parses a date like "2006-01-02" or a time in RFC 3339 format.
+// Dates are in UTC.
+func ParseTime(s string) (time.Time, error) {
+	return parseTime(s, 0)
+}
+
+// ParseEndTime is like ParseTime, but dates stand for the end of the day, so
+// that a range that ends with a date includes the whole day.
+func ParseEndTime(s string) (time.Time, error) {
+	return parseTime(s, 24*time.Hour)
+}
+
+// parseTime parses a date or a time and adds a duration to dates.
+func parseTime(s string, dateOffset time.Duration) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Add(dateOffset), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+}
@@ -0,0 +1,62 @@
+package index
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilters(t *testing.T) {
+	day := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	doc := Document{Language: "en", ContentType: "text/html", FetchTime: day.Add(15 * time.Hour)}
+	cases := []struct {
+		filter   filter
+		expected bool
+	}{
+		{Language{"en"}, true},
+		{Language{"EN"}, true},
+		{Language{"de"}, false},
+		{ContentType{"text/html"}, true},
+		{ContentType{"Text/HTML"}, true},
+		{ContentType{"text"}, true},
+		{ContentType{"text/plain"}, false},
+		{ContentType{"application"}, false},
+		{FetchedBetween{}, true},
+		{FetchedBetween{From: day}, true},
+		{FetchedBetween{From: day.Add(24 * time.Hour)}, false},
+		{FetchedBetween{To: day}, false},
+		{FetchedBetween{To: day.Add(24 * time.Hour)}, true},
+		{FetchedBetween{From: day, To: day.Add(15 * time.Hour)}, false},
+	}
+	for _, c := range cases {
+		if c.filter.accepts(doc) != c.expected {
+			t.Errorf("Expected %v for %+v", c.expected, c.filter)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	cases := []struct {
+		s        string
+		parse    func(string) (time.Time, error)
+		expected time.Time
+	}{
+		{"2024-01-05", ParseTime, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"2024-01-05", ParseEndTime, time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"2024-01-05T10:30:00Z", ParseTime, time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)},
+		{"2024-01-05T10:30:00Z", ParseEndTime, time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		parsed, err := c.parse(c.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Equal(c.expected) {
+			t.Errorf("Expected %v for %q, got %v", c.expected, c.s, parsed)
+		}
+	}
+	for _, s := range []string{"", "yesterday", "2024-13-01", "05.01.2024"} {
+		if _, err := ParseTime(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
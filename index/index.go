@@ -1,146 +1,83 @@
 package index
 
 import (
-	"encoding/csv"
 	"errors"
-	"log"
+	"go.roman.zone/crawl/analysis"
 	"net/url"
-	"os"
-	"sync"
 )
 
 var (
-	ErrMissingIndex   = errors.New("can't find the index file")
-	ErrIndexFileIsDir = errors.New("index file is a directory")
+	ErrNotDirectory = errors.New("index path is not a directory")
+	ErrClosed       = errors.New("index is closed")
+	ErrNotFound     = errors.New("document not found")
+	ErrOldFormat    = errors.New("index is stored in an older format and needs to be rebuilt")
 )
 
-// Since there's no Queue type in Go, we can just use this. Kind of hacky, but
-// that's ok. See https://github.com/golang/go/wiki/SliceTricks for more info.
-type indexType struct {
-	// Map of keywords to items
-	mapping map[string][]IndexItem
-	mutex   sync.Mutex
+// Index stores pages and allows to look them up by keywords.
+//
+// Implementations are safe for concurrent use.
+type Index interface {
+	// Add splits the page into keywords and adds it into the index. If the
+	// page is already in the index, it's replaced.
+	Add(page Page) error
+	// AddBatch adds several pages at once, which is faster than adding them
+	// one by one.
+	AddBatch(pages []Page) error
+	// Update adds the page or replaces its indexed version if any of its
+	// fields or attributes have changed. If only the fetch time is different,
+	// it's updated without reindexing the page, so that pages that are
+	// crawled again don't have to be reindexed.
+	Update(page Page) error
+	// Search returns documents that match the query.
+	Search(q Query) ([]Hit, error)
+	// DocumentFrequency returns the number of documents that contain the
+	// keyword.
+	DocumentFrequency(keyword Keyword) (int, error)
+	// Vocabulary returns keywords of the body that start with a prefix in
+	// sorted order, along with the number of documents that contain them.
+	Vocabulary(prefix Keyword) ([]KeywordCount, error)
+	// Document returns stored information about an indexed page.
+	Document(pageURL url.URL) (Document, error)
+	// Text returns the stored text of a document.
+	Text(id DocID) (string, error)
+	// Delete removes a page from the index.
+	Delete(pageURL url.URL) error
+	Stats() Stats
+	// Analyzer returns the analyzer that splits pages into keywords. Queries
+	// need to be parsed with it, so that their keywords match.
+	Analyzer() analysis.Analyzer
+	// Close releases resources used by the index. Indexes that are stored on
+	// disk are saved before closing.
+	Close() error
 }
 
-type IndexItem struct {
-	// FIXME: There might a problem with this:
-	// Same page might appear multiple times under one keyword. Though, this might
-	// be beneficial for determining how often a word appears...
-	URL url.URL
+// Hit is a document that matched a search.
+type Hit struct {
+	Document Document
+	// Postings of the document for every keyword of the query that was found
+	// in it. Postings of fields other than body are stored under keywords
+	// returned by FieldKeyword.
+	Postings map[Keyword]Posting
 }
 
-func NewIndex(filename string) *indexType {
-	index, err := importFromFile(filename)
-	if err != nil {
-		if err == ErrMissingIndex {
-			return &indexType{
-				mapping: make(map[string][]IndexItem, 0),
-			}
-		} else {
-			log.Fatal(err)
-		}
-	}
-	return index
-}
-
-func importFromFile(filename string) (*indexType, error) {
-	fileInfo, err := os.Stat(filename)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, ErrMissingIndex
-		} else {
-			return nil, err
-		}
-	}
-	if fileInfo.IsDir() {
-		return nil, ErrIndexFileIsDir
-	}
-
-	f, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-	csvReader := csv.NewReader(f)
-	csvReader.FieldsPerRecord = -1 // We have variable number of fields, so no need to do checking
-	lines, err := csvReader.ReadAll()
-	if err != nil {
-		return nil, err
-	}
-
-	indexMapping := make(map[string][]IndexItem, 0)
-
-	for _, line := range lines {
-		if len(line) < 1 {
-			continue
-		}
-		keyword := line[0]
-		indexMapping[keyword] = make([]IndexItem, 0)
-		for _, urlStr := range line[1:] {
-			parsedURL, err := url.Parse(urlStr)
-			if err != nil {
-				return nil, err
-			}
-			indexMapping[keyword] = append(indexMapping[keyword], IndexItem{URL: *parsedURL})
-		}
-	}
-
-	return &indexType{
-		mapping: indexMapping,
-	}, nil
-}
-
-func (i *indexType) AddItem(keyword string, item IndexItem) {
-	i.mutex.Lock()
-	if _, ok := i.mapping[keyword]; ok {
-		i.mapping[keyword] = append(i.mapping[keyword], item)
-	} else {
-		i.mapping[keyword] = make([]IndexItem, 0)
-		i.mapping[keyword] = append(i.mapping[keyword], item)
-	}
-	i.mutex.Unlock()
+// KeywordCount is a keyword with the number of documents that contain it.
+type KeywordCount struct {
+	Keyword   Keyword
+	Documents int
 }
 
-func (i *indexType) GetItems(keyword string) []IndexItem {
-	i.mutex.Lock()
-	defer i.mutex.Unlock()
-	if _, ok := i.mapping[keyword]; ok {
-		return i.mapping[keyword]
-	} else {
-		return make([]IndexItem, 0)
-	}
+type Stats struct {
+	Documents int
+	Keywords  int
+	Postings  int
+	// Sum of lengths of all documents
+	TotalLength int
 }
 
-func (i *indexType) Length() int {
-	i.mutex.Lock()
-	defer i.mutex.Unlock()
-	return len(i.mapping)
-}
-
-func (i *indexType) Export(filename string) error {
-	i.mutex.Lock()
-	defer i.mutex.Unlock()
-
-	f, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	w := csv.NewWriter(f)
-	for keyword, items := range i.mapping {
-		row := make([]string, 0)
-		row = append(row, keyword)
-		for _, item := range items {
-			row = append(row, item.URL.String())
-		}
-		if err := w.Write(row); err != nil {
-			return err
-		}
-	}
-	w.Flush()
-	if err := w.Error(); err != nil {
-		return err
+// AverageLength returns the average length of a document.
+func (s Stats) AverageLength() float64 {
+	if s.Documents == 0 {
+		return 0
 	}
-	return nil
+	return float64(s.TotalLength) / float64(s.Documents)
 }
@@ -0,0 +1,473 @@
+package index
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go.roman.zone/crawl/analysis"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryIndex(t *testing.T) {
+	i := NewMemoryIndex(analysis.Default)
+	page := Page{URL: url.URL{Scheme: "https", Host: "example.com"}, Content: "Go go gophers go"}
+	if err := i.Add(page); err != nil {
+		t.Fatal(err)
+	}
+	// Adding the same page again shouldn't create more postings
+	if err := i.Add(page); err != nil {
+		t.Fatal(err)
+	}
+	hits, err := i.Search(Term{"go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("Expected one hit, got %d", len(hits))
+	}
+	p := hits[0].Postings["go"]
+	if p.Frequency != 3 || len(p.Positions) != 3 || p.Positions[2] != 3 {
+		t.Errorf("Unexpected posting %+v", p)
+	}
+	// Two keywords of the body and two of the URL
+	if stats := i.Stats(); stats.Documents != 1 || stats.Keywords != 4 || stats.Postings != 4 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	if err := i.Delete(page.URL); err != nil {
+		t.Fatal(err)
+	}
+	if stats := i.Stats(); stats.Documents != 0 || stats.Postings != 0 {
+		t.Errorf("Unexpected stats after deletion %+v", stats)
+	}
+}
+
+func TestDiskIndex(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "index")
+	i, err := NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := Page{
+		URL:         url.URL{Scheme: "https", Host: "example.com"},
+		Content:     "Hello, world!",
+		Title:       "Greeting",
+		Language:    "en",
+		ContentType: "text/html",
+		FetchTime:   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Metadata:    map[string]string{"source": "test"},
+	}
+	if err := i.Add(page); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err = NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer i.Close()
+	hits, err := i.Search(Term{"world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("Expected one hit, got %d", len(hits))
+	}
+	doc, err := i.Document(page.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != hits[0].Document.ID || doc.Title != page.Title || doc.Language != page.Language ||
+		doc.ContentType != page.ContentType || !doc.FetchTime.Equal(page.FetchTime) || doc.Length != 2 || doc.Metadata["source"] != "test" {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+	if text, err := i.Text(doc.ID); err != nil || text != page.Content {
+		t.Errorf("Unexpected text %q (%v)", text, err)
+	}
+}
+
+func TestSegments(t *testing.T) {
+	dir := t.TempDir()
+	idx, err := NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := idx.(*diskIndex)
+	i.flushDocuments = 2
+	i.mergeFactor = 2
+	hosts := []string{"a", "b", "c", "d", "e", "f", "g"}
+	for _, host := range hosts {
+		page := Page{URL: url.URL{Scheme: "https", Host: host}, Content: "page about " + host}
+		if err := i.Add(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := i.Delete(url.URL{Scheme: "https", Host: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	// Replaced page gets removed from its segment
+	if err := i.Add(Page{URL: url.URL{Scheme: "https", Host: "a"}, Content: "another page"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err = NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	hits, err := idx.Search(Term{"page"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != len(hosts)-1 {
+		t.Errorf("Expected %d hits, got %d", len(hosts)-1, len(hits))
+	}
+	for _, host := range []string{"a", "b"} {
+		if hits, _ := idx.Search(Phrase{Keywords: []Keyword{"about", Keyword(host)}}); len(hits) != 0 {
+			t.Errorf("Found deleted page %q", host)
+		}
+	}
+	if hits, _ := idx.Search(Term{"another"}); len(hits) != 1 || hits[0].Document.URL.Host != "a" {
+		t.Errorf("Unexpected hits for the updated page %+v", hits)
+	}
+	// Keywords of the body and the host of each page
+	if stats := idx.Stats(); stats.Documents != 6 || stats.Postings != 5*3+2+6 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestCorruptSegment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "segment")
+	docs := []Document{{ID: 1, URL: url.URL{Scheme: "https", Host: "a"}}}
+	postings := map[Keyword][]Posting{"crawler": {{DocID: 1, Positions: []int{0}}}}
+	if err := writeSegment(path, docs, map[DocID][]byte{}, postings); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseSegment("segment", data); err != nil {
+		t.Fatal(err)
+	}
+	footer := len(data) - segmentFooterSize
+	termTable := int(binary.LittleEndian.Uint64(data[footer+2*8:]))
+	cases := map[string]struct {
+		offset int
+		// Size of the value in bytes
+		size  int
+		value uint64
+	}{
+		"negative term count": {footer + 3*8, 8, 1 << 63},
+		"no documents":        {footer + 1*8, 8, 0},
+		"term offset":         {termTable, 4, 1 << 20},
+		"term length":         {termTable + 4, 4, 1 << 20},
+		"postings offset":     {termTable + 8, 8, uint64(len(data))},
+	}
+	for name, c := range cases {
+		corrupt := append([]byte(nil), data...)
+		if c.size == 8 {
+			binary.LittleEndian.PutUint64(corrupt[c.offset:], c.value)
+		} else {
+			binary.LittleEndian.PutUint32(corrupt[c.offset:], uint32(c.value))
+		}
+		if _, err := parseSegment("segment", corrupt); err != ErrCorruptSegment {
+			t.Errorf("Expected ErrCorruptSegment for %s, got %v", name, err)
+		}
+	}
+}
+
+func TestOldFormat(t *testing.T) {
+	dir := t.TempDir()
+	csv := filepath.Join(dir, "index.csv")
+	if err := os.WriteFile(csv, []byte("crawler,https://example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDiskIndex(csv, ""); err != ErrOldFormat {
+		t.Errorf("Expected ErrOldFormat for a CSV index, got %v", err)
+	}
+
+	idx, err := NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx.(*diskIndex).flushDocuments = 1
+	if err := idx.Add(Page{URL: url.URL{Scheme: "https", Host: "a"}, Content: "page"}); err != nil {
+		t.Fatal(err)
+	}
+	segment := filepath.Join(dir, idx.(*diskIndex).segments[0].name)
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(SEGMENT_MAGIC)] = SEGMENT_VERSION - 1
+	if err := os.WriteFile(segment, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDiskIndex(dir, ""); !errors.Is(err, ErrOldFormat) {
+		t.Errorf("Expected ErrOldFormat for an old segment, got %v", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	dir := t.TempDir()
+	idx, err := NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := idx.(*diskIndex)
+	i.flushDocuments = 2
+	i.mergeFactor = 10
+	pages := []Page{
+		{URL: url.URL{Scheme: "https", Host: "a"}, Content: "first version"},
+		{URL: url.URL{Scheme: "https", Host: "b"}, Content: "unchanged"},
+	}
+	for _, page := range pages {
+		if err := i.Update(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first, _ := i.Document(pages[1].URL)
+	// Both pages are in a segment now, so replacing one of them leaves a
+	// tombstone, which is enough to compact the segment.
+	pages[0].Content = "second version"
+	for _, page := range pages {
+		if err := i.Update(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if doc, _ := i.Document(pages[1].URL); doc.ID != first.ID {
+		t.Errorf("Unchanged page was reindexed")
+	}
+	if hits, _ := i.Search(Term{"first"}); len(hits) != 0 {
+		t.Errorf("Found the old version of the page")
+	}
+	// Changes of attributes are stored even if the content is the same
+	fetchTime := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+	pages[1].Title = "New title"
+	pages[1].FetchTime = fetchTime
+	if err := i.Update(pages[1]); err != nil {
+		t.Fatal(err)
+	}
+	if doc, _ := i.Document(pages[1].URL); doc.Title != "New title" || !doc.FetchTime.Equal(fetchTime) {
+		t.Errorf("Attributes of the page weren't updated: %+v", doc)
+	}
+	if err := i.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err = NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if deleted := len(idx.(*diskIndex).deleted); deleted != 0 {
+		t.Errorf("Expected deleted documents to be purged, %d left", deleted)
+	}
+	if hits, _ := idx.Search(Term{"version"}); len(hits) != 1 {
+		t.Errorf("Expected one hit, got %d", len(hits))
+	}
+}
+
+func TestUpdateFetchTime(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Page is written into a segment right away
+	disk.(*diskIndex).flushDocuments = 1
+	page := Page{URL: url.URL{Scheme: "https", Host: "a"}, Content: "same content"}
+	first := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+	for _, idx := range []Index{NewMemoryIndex(analysis.Default), disk} {
+		page.FetchTime = first
+		if err := idx.Update(page); err != nil {
+			t.Fatal(err)
+		}
+		indexed, _ := idx.Document(page.URL)
+		for n := 1; n <= 2; n++ {
+			page.FetchTime = first.Add(time.Duration(n) * time.Hour)
+			if err := idx.Update(page); err != nil {
+				t.Fatal(err)
+			}
+			doc, _ := idx.Document(page.URL)
+			if doc.ID != indexed.ID {
+				t.Errorf("Page with a new fetch time was reindexed")
+			}
+			if !doc.FetchTime.Equal(page.FetchTime) {
+				t.Errorf("Expected fetch time %v, got %v", page.FetchTime, doc.FetchTime)
+			}
+		}
+	}
+	if err := disk.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	disk, err = NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer disk.Close()
+	if doc, _ := disk.Document(page.URL); !doc.FetchTime.Equal(page.FetchTime) {
+		t.Errorf("Expected fetch time %v after reopening, got %v", page.FetchTime, doc.FetchTime)
+	}
+	hits, _ := disk.Search(FetchedBetween{From: page.FetchTime})
+	if len(hits) != 1 || !hits[0].Document.FetchTime.Equal(page.FetchTime) {
+		t.Errorf("Unexpected hits %+v", hits)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	dir := t.TempDir()
+	i, err := NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range []string{"a", "b", "c"} {
+		if err := i.Add(Page{URL: url.URL{Scheme: "https", Host: host}, Content: "lost page"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := i.Delete(url.URL{Scheme: "https", Host: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	// Index is not closed, as if the program crashed. The last record is only
+	// partially written.
+	f, err := os.OpenFile(filepath.Join(dir, LOG_FILE), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{100, 0, 0, 0, 1, 2})
+	f.Close()
+
+	i, err = NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer i.Close()
+	hits, err := i.Search(Term{"lost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 {
+		t.Errorf("Expected 2 recovered pages, got %d", len(hits))
+	}
+	if err := i.Add(Page{URL: url.URL{Scheme: "https", Host: "d"}, Content: "new page"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	dir := t.TempDir()
+	i, err := NewDiskIndex(dir, "english")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Add(Page{URL: url.URL{Scheme: "https", Host: "a"}, Content: "The crawlers are crawling"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDiskIndex(dir, analysis.DEFAULT_ANALYZER); err == nil {
+		t.Error("Expected an error when opening with a different analyzer")
+	}
+
+	// Analyzer is stored in the index
+	i, err = NewDiskIndex(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer i.Close()
+	for q, expected := range map[string]int{"crawled": 1, "crawler": 1, "the": 0} {
+		query, err := ParseQuery(q, i.Analyzer())
+		if err == ErrEmptyQuery {
+			query = Term{Keyword(q)}
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if hits, _ := i.Search(query); len(hits) != expected {
+			t.Errorf("Expected %d hits for %q, got %d", expected, q, len(hits))
+		}
+	}
+}
+
+func TestIndexer(t *testing.T) {
+	i := NewMemoryIndex(analysis.Default)
+	var indexed int32
+	x := NewIndexer(i, 4, func(page Page, err error) {
+		if err != nil {
+			t.Error(err)
+		}
+		atomic.AddInt32(&indexed, 1)
+	})
+	for n := 0; n < 100; n++ {
+		page := Page{URL: url.URL{Host: fmt.Sprintf("%d.example.com", n)}, Content: "indexed concurrently"}
+		if err := x.Add(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	x.Close()
+	if err := x.Add(Page{}); err != ErrClosed {
+		t.Errorf("Expected ErrClosed after closing, got %v", err)
+	}
+	if indexed != 100 {
+		t.Errorf("Expected 100 indexed pages, got %d", indexed)
+	}
+	if hits, _ := i.Search(Term{"concurrently"}); len(hits) != 100 {
+		t.Errorf("Expected 100 hits, got %d", len(hits))
+	}
+}
+
+func TestPhrase(t *testing.T) {
+	i := NewMemoryIndex(analysis.Default)
+	pages := map[string]string{
+		"exact":    "Introduction to machine learning",
+		"near":     "Machine based learning",
+		"reversed": "Learning about the machine",
+		"crawler":  "Web crawler",
+		"repeated": "Web pages for the web",
+	}
+	for host, content := range pages {
+		if err := i.Add(Page{URL: url.URL{Host: host}, Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := []struct {
+		query    Phrase
+		expected int
+	}{
+		{NewPhrase(analysis.Default, "machine learning", 0), 1},
+		{NewPhrase(analysis.Default, "machine learning", 1), 2},
+		{NewPhrase(analysis.Default, "machine learning", 2), 3},
+		{NewPhrase(analysis.Default, "learning machine", 0), 0},
+		// Each copy of a repeated keyword needs its own occurrence
+		{NewPhrase(analysis.Default, "web web", 2), 0},
+		{NewPhrase(analysis.Default, "web web", 3), 1},
+		{NewPhrase(analysis.Default, "crawler web crawler", 3), 0},
+		{NewPhrase(analysis.Default, "web crawler", 1), 1},
+	}
+	for _, c := range cases {
+		hits, err := i.Search(c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hits) != c.expected {
+			t.Errorf("Expected %d hits for %+v, got %d", c.expected, c.query, len(hits))
+		}
+	}
+}
@@ -1,40 +1,124 @@
 package index
 
 import (
-	"fmt"
+	"go.roman.zone/crawl/analysis"
 	"net/url"
-	"regexp"
-	"strings"
+	"sync"
+	"time"
 )
 
 const (
-	KEYWORD_EXCLUDE_REGEX = "[^\\w]"
+	STORAGE_DIR = "index"
 
-	STORAGE_FILE = "index.csv"
-)
-
-var (
-	Index = NewIndex(STORAGE_FILE)
+	// Number of pages that can wait for indexing before Indexer.Add blocks
+	INDEXER_QUEUE_SIZE = 256
+	// Maximum number of pages added into the index at once
+	INDEXER_BATCH_SIZE = 32
 )
 
+// Page is a page to be indexed. Each field of the page is indexed
+// separately.
 type Page struct {
-	URL     url.URL
+	URL url.URL
+	// Text of the page
 	Content string
+	Title   string
+	// Text of headings. It's also a part of the content.
+	Headings []string
+	// Text of links to the page from other pages
+	Anchors  []string
+	Language string
+	// MIME type like "text/html"
+	ContentType string
+	FetchTime   time.Time
+	Metadata    map[string]string
+}
+
+// Keywords splits text into keywords with an analyzer. Pages and queries of
+// an index need to be split with the same analyzer, the one returned by
+// Index.Analyzer.
+func Keywords(analyzer analysis.Analyzer, content string) []Keyword {
+	terms := analyzer.Terms(content)
+	keywords := make([]Keyword, len(terms))
+	for i, t := range terms {
+		keywords[i] = Keyword(t)
+	}
+	return keywords
+}
+
+// Indexer adds pages into an index in the background, so that callers don't
+// have to wait for it. Pages are put into a queue and taken from it by a pool
+// of goroutines, each of which analyzes pages and adds them into the index in
+// batches.
+type Indexer struct {
+	index Index
+	pages chan Page
+	// Called after a page has been added or failed to be added
+	done    func(page Page, err error)
+	workers sync.WaitGroup
+	closed  bool
+	mutex   sync.RWMutex
 }
 
-func ProcessPage(page Page) {
-	fmt.Println("Indexed page:", page.URL.String())
+// NewIndexer starts indexing goroutines. The done function can be nil.
+func NewIndexer(idx Index, workers int, done func(page Page, err error)) *Indexer {
+	x := &Indexer{
+		index: idx,
+		pages: make(chan Page, INDEXER_QUEUE_SIZE),
+		done:  done,
+	}
+	for n := 0; n < workers; n++ {
+		x.workers.Add(1)
+		go x.work()
+	}
+	return x
+}
 
-	words := strings.Fields(page.Content)
-	for _, w := range words {
-		Index.AddItem(prepKeyword(w), IndexItem{
-			URL: page.URL,
-		})
+func (x *Indexer) work() {
+	defer x.workers.Done()
+	for page := range x.pages {
+		// Taking whatever else is in the queue without waiting
+		batch := []Page{page}
+	collect:
+		for len(batch) < INDEXER_BATCH_SIZE {
+			select {
+			case page, ok := <-x.pages:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, page)
+			default:
+				break collect
+			}
+		}
+		err := x.index.AddBatch(batch)
+		if x.done != nil {
+			for _, page := range batch {
+				x.done(page, err)
+			}
+		}
 	}
 }
 
-func prepKeyword(keyword string) string {
-	keyword = strings.ToLower(keyword)
-	re := regexp.MustCompile(KEYWORD_EXCLUDE_REGEX)
-	return re.ReplaceAllString(keyword, "")
+// Add queues a page for indexing. It only blocks if the queue is full.
+func (x *Indexer) Add(page Page) error {
+	x.mutex.RLock()
+	defer x.mutex.RUnlock()
+	if x.closed {
+		return ErrClosed
+	}
+	x.pages <- page
+	return nil
+}
+
+// Close waits until all queued pages are indexed. The index itself is not
+// closed.
+func (x *Indexer) Close() {
+	x.mutex.Lock()
+	if !x.closed {
+		x.closed = true
+		close(x.pages)
+	}
+	x.mutex.Unlock()
+	x.workers.Wait()
 }
@@ -1,11 +1,14 @@
 package index
 
-import "testing"
+import (
+	"go.roman.zone/crawl/analysis"
+	"reflect"
+	"testing"
+)
 
-func TestPrepKeyword(t *testing.T) {
-	dirtyWord := "Test!123"
-	cleanKeyword := Keyword("test123")
-	if prepKeyword(dirtyWord) != cleanKeyword {
-		t.Error("Didn't clean the keyword properly")
+func TestKeywords(t *testing.T) {
+	expected := []Keyword{"test", "123", "c++", "don't", "cafe"}
+	if keywords := Keywords(analysis.Default, "Test!123 C++ don't café"); !reflect.DeepEqual(keywords, expected) {
+		t.Errorf("Expected %q, got %q", expected, keywords)
 	}
 }
@@ -0,0 +1,183 @@
+package index
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	LINKS_FILE    = "links.log"
+	PAGERANK_FILE = "pagerank.json"
+
+	// Links are rewritten when opened if there are more records than this
+	// many times the number of pages, since only the last record of each
+	// page matters.
+	LINKS_COMPACT_RATIO = 2
+)
+
+// LinkGraph stores links between pages. For every page it keeps URLs of the
+// pages it links to, as they were the last time the page was crawled.
+//
+// Each change is appended to a file in the index directory as a record with
+// the URL of the page followed by URLs of the links.
+type LinkGraph struct {
+	path    string
+	records *recordFile
+	links   map[string][]string
+	// Number of records in the file
+	count int
+	mutex sync.Mutex
+}
+
+// OpenLinkGraph opens a link graph in a directory, which is created if it
+// doesn't exist.
+func OpenLinkGraph(dir string) (*LinkGraph, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	g := &LinkGraph{path: filepath.Join(dir, LINKS_FILE), links: make(map[string][]string)}
+	var err error
+	g.records, err = openRecordFile(g.path, g.apply)
+	if err != nil {
+		return nil, err
+	}
+	if g.count > LINKS_COMPACT_RATIO*len(g.links) {
+		if err := g.compact(); err != nil {
+			if g.records != nil {
+				g.records.close()
+			}
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// ReadLinks reads links stored in a directory without opening the graph for
+// writing, so that it can be done while the crawler is running. It returns a
+// map of page URLs to URLs of pages they link to.
+func ReadLinks(dir string) (map[string][]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, LINKS_FILE))
+	if err != nil {
+		return nil, err
+	}
+	g := &LinkGraph{links: make(map[string][]string)}
+	readRecords(data, g.apply)
+	return g.links, nil
+}
+
+func (g *LinkGraph) apply(payload []byte) bool {
+	r := &byteReader{data: payload}
+	source := r.string()
+	targets := r.strings()
+	if r.err != nil {
+		return false
+	}
+	g.links[source] = targets
+	g.count++
+	return true
+}
+
+// compact rewrites the file with a single record for every page. Records are
+// nil if it fails after the file is closed.
+func (g *LinkGraph) compact() error {
+	data := make([]byte, 0)
+	for source, targets := range g.links {
+		data = appendRecord(data, encodeLinks(source, targets))
+	}
+	err := g.records.close()
+	g.records = nil
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(g.path, data); err != nil {
+		return err
+	}
+	records, err := openRecordFile(g.path, func([]byte) bool { return true })
+	if err != nil {
+		return err
+	}
+	g.records = records
+	g.count = len(g.links)
+	return nil
+}
+
+func encodeLinks(source string, targets []string) []byte {
+	b := appendString(nil, source)
+	return appendStrings(b, targets)
+}
+
+// SetLinks replaces links of a page. Repeated links and links to the page
+// itself are skipped.
+func (g *LinkGraph) SetLinks(source url.URL, targets []url.URL) error {
+	s := source.String()
+	seen := map[string]bool{s: true}
+	links := make([]string, 0, len(targets))
+	for _, t := range targets {
+		if target := t.String(); !seen[target] {
+			seen[target] = true
+			links = append(links, target)
+		}
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.records == nil {
+		return ErrClosed
+	}
+	if err := g.records.append(encodeLinks(s, links)); err != nil {
+		return err
+	}
+	g.links[s] = links
+	g.count++
+	return nil
+}
+
+// Links returns a copy of all links as a map of page URLs to URLs of pages
+// they link to.
+func (g *LinkGraph) Links() map[string][]string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	links := make(map[string][]string, len(g.links))
+	for source, targets := range g.links {
+		links[source] = targets
+	}
+	return links
+}
+
+func (g *LinkGraph) Close() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.records == nil {
+		return nil
+	}
+	err := g.records.close()
+	g.records = nil
+	return err
+}
+
+// WritePageRanks saves PageRank scores of pages by their URLs into a
+// directory.
+func WritePageRanks(dir string, scores map[string]float64) error {
+	data, err := json.Marshal(scores)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, PAGERANK_FILE), data)
+}
+
+// ReadPageRanks reads scores saved with WritePageRanks. The error satisfies
+// os.IsNotExist if there are no scores.
+func ReadPageRanks(dir string) (map[string]float64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PAGERANK_FILE))
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[string]float64)
+	if err := json.Unmarshal(data, &scores); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
@@ -0,0 +1,60 @@
+package index
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestLinkGraph(t *testing.T) {
+	dir := t.TempDir()
+	g, err := OpenLinkGraph(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := url.URL{Scheme: "https", Host: "a"}
+	b := url.URL{Scheme: "https", Host: "b"}
+	c := url.URL{Scheme: "https", Host: "c"}
+	// Links are replaced when a page is crawled again
+	for i := 0; i < 3; i++ {
+		if err := g.SetLinks(a, []url.URL{b, b, a}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.SetLinks(b, []url.URL{a, c}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.SetLinks(a, []url.URL{c}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{"https://a": {"https://c"}, "https://b": {"https://a", "https://c"}}
+	if links := g.Links(); !reflect.DeepEqual(links, expected) {
+		t.Errorf("Expected %v, got %v", expected, links)
+	}
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if links, err := ReadLinks(dir); err != nil || !reflect.DeepEqual(links, expected) {
+		t.Errorf("Expected %v, got %v (%v)", expected, links, err)
+	}
+	// Reopening compacts the records
+	g, err = OpenLinkGraph(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.count != 2 || !reflect.DeepEqual(g.Links(), expected) {
+		t.Errorf("Unexpected links after compaction %v (%d records)", g.Links(), g.count)
+	}
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	scores := map[string]float64{"https://a": 1.5, "https://c": 0.5}
+	if err := WritePageRanks(dir, scores); err != nil {
+		t.Fatal(err)
+	}
+	if read, err := ReadPageRanks(dir); err != nil || !reflect.DeepEqual(read, scores) {
+		t.Errorf("Expected %v, got %v (%v)", scores, read, err)
+	}
+}
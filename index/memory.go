@@ -0,0 +1,245 @@
+package index
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"net/url"
+	"sort"
+	"sync"
+)
+
+// memoryIndex keeps everything in memory. Contents are lost when it's closed.
+type memoryIndex struct {
+	// Map of keywords to postings sorted by DocID
+	mapping map[Keyword][]Posting
+	// Keywords of the mapping in sorted order, nil when they need to be
+	// sorted again
+	sorted    []Keyword
+	documents *documentStore
+	analyzer  analysis.Analyzer
+	closed    bool
+	mutex     sync.Mutex
+}
+
+// NewMemoryIndex creates an empty index that splits pages into keywords with
+// an analyzer.
+func NewMemoryIndex(analyzer analysis.Analyzer) Index {
+	return newMemoryIndex(analyzer)
+}
+
+func newMemoryIndex(analyzer analysis.Analyzer) *memoryIndex {
+	return &memoryIndex{
+		mapping:   make(map[Keyword][]Posting, 0),
+		documents: newDocumentStore(),
+		analyzer:  analyzer,
+	}
+}
+
+func (i *memoryIndex) Analyzer() analysis.Analyzer {
+	return i.analyzer
+}
+
+func (i *memoryIndex) Add(page Page) error {
+	return i.AddBatch([]Page{page})
+}
+
+func (i *memoryIndex) AddBatch(pages []Page) error {
+	// Pages are analyzed before locking, so that adding pages from multiple
+	// goroutines isn't serialized on that.
+	analyzed := make([]analyzedPage, len(pages))
+	for n, page := range pages {
+		analyzed[n] = analyzePage(page, i.analyzer)
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return ErrClosed
+	}
+	for _, a := range analyzed {
+		i.add(a)
+	}
+	return nil
+}
+
+func (i *memoryIndex) Update(page Page) error {
+	a := analyzePage(page, i.analyzer)
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return ErrClosed
+	}
+	if doc, ok := i.documents.lookup(page.URL); ok && doc.Hash == pageHash(page) {
+		doc.FetchTime = page.FetchTime
+		i.documents.put(doc)
+		return nil
+	}
+	i.add(a)
+	return nil
+}
+
+// add adds a page or replaces it. Caller must hold the mutex.
+func (i *memoryIndex) add(a analyzedPage) {
+	// New ID is assigned even if the page is already indexed, so that
+	// postings stay sorted.
+	i.delete(a.page.URL)
+	doc := i.documents.newDocument(a.page, a.length())
+	i.addDocument(doc, a.postings(doc.ID))
+	i.documents.putText(doc.ID, a.text)
+}
+
+// addDocument stores a document with its postings. Caller must hold the mutex.
+func (i *memoryIndex) addDocument(doc Document, postings map[Keyword]Posting) {
+	i.documents.put(doc)
+	for keyword, p := range postings {
+		if _, ok := i.mapping[keyword]; !ok {
+			i.sorted = nil
+		}
+		i.mapping[keyword] = append(i.mapping[keyword], p)
+	}
+}
+
+func (i *memoryIndex) Search(q Query) ([]Hit, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return nil, ErrClosed
+	}
+	return search(i, q), nil
+}
+
+// postings implements postingsSource. Caller must hold the mutex.
+func (i *memoryIndex) postings(keyword Keyword) []Posting {
+	return i.mapping[keyword]
+}
+
+func (i *memoryIndex) document(id DocID) (Document, bool) {
+	return i.documents.get(id)
+}
+
+func (i *memoryIndex) allDocuments() []DocID {
+	return i.documents.allIDs()
+}
+
+func (i *memoryIndex) keywords(prefix Keyword) []Keyword {
+	if i.sorted == nil {
+		i.sorted = make([]Keyword, 0, len(i.mapping))
+		for keyword := range i.mapping {
+			i.sorted = append(i.sorted, keyword)
+		}
+		sort.Slice(i.sorted, func(a, b int) bool { return i.sorted[a] < i.sorted[b] })
+	}
+	return keywordsWithPrefix(len(i.sorted), func(n int) Keyword { return i.sorted[n] }, prefix)
+}
+
+func (i *memoryIndex) Vocabulary(prefix Keyword) ([]KeywordCount, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return nil, ErrClosed
+	}
+	return i.keywordCounts(prefix), nil
+}
+
+// keywordCounts returns keywords of the body with their document frequencies.
+// Caller must hold the mutex.
+func (i *memoryIndex) keywordCounts(prefix Keyword) []KeywordCount {
+	counts := make([]KeywordCount, 0)
+	for _, k := range i.keywords(prefix) {
+		if field, _ := SplitFieldKeyword(k); field == FIELD_BODY {
+			counts = append(counts, KeywordCount{Keyword: k, Documents: len(i.mapping[k])})
+		}
+	}
+	return counts
+}
+
+func (i *memoryIndex) DocumentFrequency(keyword Keyword) (int, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return 0, ErrClosed
+	}
+	return len(i.mapping[keyword]), nil
+}
+
+func (i *memoryIndex) Document(pageURL url.URL) (Document, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return Document{}, ErrClosed
+	}
+	doc, ok := i.documents.lookup(pageURL)
+	if !ok {
+		return Document{}, ErrNotFound
+	}
+	return doc, nil
+}
+
+func (i *memoryIndex) Text(id DocID) (string, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return "", ErrClosed
+	}
+	text, ok := i.documents.text(id)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return decompressText(text)
+}
+
+func (i *memoryIndex) Delete(pageURL url.URL) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.closed {
+		return ErrClosed
+	}
+	i.delete(pageURL)
+	return nil
+}
+
+// delete removes a page with all its postings. Caller must hold the mutex.
+func (i *memoryIndex) delete(pageURL url.URL) {
+	doc, ok := i.documents.lookup(pageURL)
+	if !ok {
+		return
+	}
+	id := doc.ID
+	i.documents.delete(id)
+	for keyword, postings := range i.mapping {
+		if _, found := findPosting(postings, id); !found {
+			continue
+		}
+		kept := make([]Posting, 0, len(postings)-1)
+		for _, p := range postings {
+			if p.DocID != id {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			delete(i.mapping, keyword)
+			i.sorted = nil
+		} else {
+			i.mapping[keyword] = kept
+		}
+	}
+}
+
+func (i *memoryIndex) Stats() Stats {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	stats := Stats{
+		Documents:   i.documents.len(),
+		Keywords:    len(i.mapping),
+		TotalLength: i.documents.totalLength,
+	}
+	for _, postings := range i.mapping {
+		stats.Postings += len(postings)
+	}
+	return stats
+}
+
+func (i *memoryIndex) Close() error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.closed = true
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package index
+
+import (
+	"os"
+)
+
+// mapFile reads contents of a file into memory. Memory mapping is only used on
+// Unix systems.
+func mapFile(path string) ([]byte, func() error, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,39 @@
+package index
+
+import (
+	"sort"
+)
+
+// Keyword is a normalized word as it's stored in the index.
+type Keyword string
+
+// Posting records occurrences of a keyword in a single document.
+type Posting struct {
+	DocID DocID
+	// Number of times the keyword appears in the document
+	Frequency int
+	// Positions of the keyword among all the keywords of the document
+	Positions []int
+}
+
+// postingsFor groups keywords of a document into postings.
+func postingsFor(id DocID, keywords []Keyword) map[Keyword]Posting {
+	postings := make(map[Keyword]Posting)
+	for position, keyword := range keywords {
+		p := postings[keyword]
+		p.DocID = id
+		p.Frequency++
+		p.Positions = append(p.Positions, position)
+		postings[keyword] = p
+	}
+	return postings
+}
+
+// findPosting looks up a posting for a document in a list sorted by DocID.
+func findPosting(postings []Posting, id DocID) (Posting, bool) {
+	i := sort.Search(len(postings), func(i int) bool { return postings[i].DocID >= id })
+	if i < len(postings) && postings[i].DocID == id {
+		return postings[i], true
+	}
+	return Posting{}, false
+}
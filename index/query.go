@@ -0,0 +1,446 @@
+package index
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"sort"
+	"strings"
+)
+
+// Query describes which documents to find. Queries are trees built from Term,
+// Phrase, And, Or, Not, FieldTerm, Prefix, Wildcard, Fuzzy and filters like
+// Site, Language, ContentType and FetchedBetween. They can also be parsed from
+// text with ParseQuery.
+type Query interface {
+	// execute returns matching documents sorted by DocID.
+	execute(s postingsSource) []match
+}
+
+// filter is a query that can be checked against each document separately.
+// Executing filters on their own requires going through every document, so
+// it's better to apply them to results of other queries when possible.
+type filter interface {
+	Query
+	accepts(doc Document) bool
+}
+
+// postingsSource provides postings lists sorted by DocID and stored
+// documents.
+type postingsSource interface {
+	postings(keyword Keyword) []Posting
+	document(id DocID) (Document, bool)
+	// allDocuments returns IDs of all documents in ascending order.
+	allDocuments() []DocID
+	// keywords returns keywords that start with a prefix in sorted order.
+	keywords(prefix Keyword) []Keyword
+}
+
+// match is a document that matched a query along with postings of the
+// keywords that matched.
+type match struct {
+	id       DocID
+	postings map[Keyword]Posting
+}
+
+// Term matches documents that contain a keyword in any of the fields.
+type Term struct {
+	Keyword Keyword
+}
+
+func (q Term) execute(s postingsSource) []match {
+	result := make([]match, 0)
+	for _, field := range Fields {
+		result = union(result, keywordMatches(s, FieldKeyword(field, q.Keyword)))
+	}
+	return result
+}
+
+func keywordMatches(s postingsSource, keyword Keyword) []match {
+	postings := s.postings(keyword)
+	matches := make([]match, len(postings))
+	for i, p := range postings {
+		matches[i] = match{id: p.DocID, postings: map[Keyword]Posting{keyword: p}}
+	}
+	return matches
+}
+
+// Or matches documents that match at least one of the queries.
+type Or []Query
+
+func (q Or) execute(s postingsSource) []match {
+	result := make([]match, 0)
+	for _, subquery := range q {
+		result = union(result, subquery.execute(s))
+	}
+	return result
+}
+
+// And matches documents that match all of the queries. Not queries inside of
+// And exclude documents from the results.
+type And []Query
+
+func (q And) execute(s postingsSource) []match {
+	positive := make([][]match, 0, len(q))
+	filters := make([]filter, 0)
+	negative := make([][]match, 0)
+	for _, subquery := range q {
+		switch subquery := subquery.(type) {
+		case Not:
+			negative = append(negative, subquery.Query.execute(s))
+		case filter:
+			filters = append(filters, subquery)
+		default:
+			positive = append(positive, subquery.execute(s))
+		}
+	}
+
+	var result []match
+	if len(positive) == 0 {
+		if len(filters) > 0 {
+			// Executing one of the filters is still better than going through
+			// every document with all of them.
+			result = filters[0].execute(s)
+			filters = filters[1:]
+		} else {
+			result = allMatches(s)
+		}
+	} else {
+		// Starting from the shortest lists keeps intermediate results small.
+		sort.Slice(positive, func(i, j int) bool { return len(positive[i]) < len(positive[j]) })
+		result = positive[0]
+		for _, list := range positive[1:] {
+			result = intersect(result, list)
+		}
+	}
+	for _, list := range negative {
+		result = difference(result, list)
+	}
+	if len(filters) > 0 {
+		result = applyFilters(s, result, filters)
+	}
+	return result
+}
+
+// Not matches documents that don't match the query.
+type Not struct {
+	Query Query
+}
+
+func (q Not) execute(s postingsSource) []match {
+	return difference(allMatches(s), q.Query.execute(s))
+}
+
+// Site matches documents from a host or any of its subdomains.
+type Site struct {
+	Host string
+}
+
+func (q Site) execute(s postingsSource) []match {
+	return applyFilters(s, allMatches(s), []filter{q})
+}
+
+func (q Site) accepts(doc Document) bool {
+	host := strings.ToLower(doc.URL.Hostname())
+	site := strings.ToLower(q.Host)
+	return host == site || strings.HasSuffix(host, "."+site)
+}
+
+// FieldTerm matches documents that have a keyword in a field.
+type FieldTerm struct {
+	Field   Field
+	Keyword Keyword
+}
+
+func (q FieldTerm) execute(s postingsSource) []match {
+	return keywordMatches(s, FieldKeyword(q.Field, q.Keyword))
+}
+
+// Phrase matches documents that contain all the keywords next to each other
+// in the same order. If Slop is greater than zero, keywords can be in any
+// order and it's enough for them to be within a window of len(Keywords)+Slop
+// keywords. For example, with Slop of 1 "machine learning" would match both
+// "machine deep learning" and "learning machine".
+type Phrase struct {
+	Keywords []Keyword
+	Slop     int
+	// Field to look for the phrase in, body by default
+	Field Field
+}
+
+// NewPhrase creates a phrase query from text, which is split into keywords
+// with an analyzer.
+func NewPhrase(analyzer analysis.Analyzer, text string, slop int) Phrase {
+	return Phrase{Keywords: Keywords(analyzer, text), Slop: slop}
+}
+
+func (q Phrase) execute(s postingsSource) []match {
+	if len(q.Keywords) == 0 {
+		return []match{}
+	}
+	keywords := make([]Keyword, len(q.Keywords))
+	lists := make([][]Posting, len(q.Keywords))
+	for i, keyword := range q.Keywords {
+		keywords[i] = FieldKeyword(q.Field, keyword)
+		lists[i] = s.postings(keywords[i])
+	}
+
+	result := make([]match, 0)
+	// Walking through postings of the first keyword and looking up the same
+	// documents in the other lists.
+	for _, first := range lists[0] {
+		positions := make([][]int, len(q.Keywords))
+		m := match{id: first.DocID, postings: make(map[Keyword]Posting, len(q.Keywords))}
+		found := true
+		for i, list := range lists {
+			p, ok := findPosting(list, first.DocID)
+			if !ok {
+				found = false
+				break
+			}
+			positions[i] = p.Positions
+			m.postings[keywords[i]] = p
+		}
+		if found && q.matches(positions) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// matches checks positions of each keyword of the phrase within a document.
+func (q Phrase) matches(positions [][]int) bool {
+	if q.Slop <= 0 {
+		for _, start := range positions[0] {
+			if hasSequence(positions, start) {
+				return true
+			}
+		}
+		return false
+	}
+	// Repeated keywords need a separate position for each of their copies
+	lists := make([][]int, 0, len(positions))
+	needed := make([]int, 0, len(positions))
+	seen := make(map[Keyword]int, len(positions))
+	for i, keyword := range q.Keywords {
+		if n, ok := seen[keyword]; ok {
+			needed[n]++
+			continue
+		}
+		seen[keyword] = len(lists)
+		lists = append(lists, positions[i])
+		needed = append(needed, 1)
+	}
+	window := minWindow(lists, needed)
+	return window >= 0 && window <= len(q.Keywords)-1+q.Slop
+}
+
+// hasSequence checks if every keyword appears right after the previous one,
+// starting from a given position.
+func hasSequence(positions [][]int, start int) bool {
+	for i := 1; i < len(positions); i++ {
+		if !containsPosition(positions[i], start+i) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPosition(positions []int, pos int) bool {
+	i := sort.SearchInts(positions, pos)
+	return i < len(positions) && positions[i] == pos
+}
+
+// minWindow returns the smallest distance between the first and the last
+// position in a window that includes needed[i] different positions from list
+// i, or -1 if there's no such window. Lists need to be sorted and can't have
+// positions in common.
+func minWindow(lists [][]int, needed []int) int {
+	type entry struct{ pos, list int }
+	entries := make([]entry, 0)
+	for i, list := range lists {
+		for _, pos := range list {
+			entries = append(entries, entry{pos, i})
+		}
+	}
+	sort.Slice(entries, func(a, b int) bool { return entries[a].pos < entries[b].pos })
+
+	best := -1
+	counts := make([]int, len(lists))
+	// Number of lists that have enough positions in the window
+	complete := 0
+	start := 0
+	for _, e := range entries {
+		if counts[e.list]++; counts[e.list] == needed[e.list] {
+			complete++
+		}
+		// Shrinking the window from the start while it stays complete
+		for complete == len(lists) {
+			first := entries[start]
+			if best < 0 || e.pos-first.pos < best {
+				best = e.pos - first.pos
+			}
+			if counts[first.list]--; counts[first.list] < needed[first.list] {
+				complete--
+			}
+			start++
+		}
+	}
+	return best
+}
+
+// QueryKeywords returns keywords that documents matching a query are expected
+// to contain. Keywords of excluded parts of the query are not included, and
+// neither are keywords of prefix, wildcard and fuzzy queries, which are only
+// known after searching.
+func QueryKeywords(q Query) []Keyword {
+	switch q := q.(type) {
+	case Term:
+		return []Keyword{q.Keyword}
+	case FieldTerm:
+		return []Keyword{q.Keyword}
+	case Phrase:
+		return q.Keywords
+	case And:
+		return subqueryKeywords(q)
+	case Or:
+		return subqueryKeywords(q)
+	}
+	return nil
+}
+
+func subqueryKeywords(queries []Query) []Keyword {
+	keywords := make([]Keyword, 0)
+	for _, subquery := range queries {
+		keywords = append(keywords, QueryKeywords(subquery)...)
+	}
+	return keywords
+}
+
+// search executes a query and looks up documents that matched it.
+func search(s postingsSource, q Query) []Hit {
+	matches := q.execute(s)
+	hits := make([]Hit, 0, len(matches))
+	for _, m := range matches {
+		if doc, ok := s.document(m.id); ok {
+			hits = append(hits, Hit{Document: doc, Postings: m.postings})
+		}
+	}
+	return hits
+}
+
+func allMatches(s postingsSource) []match {
+	ids := s.allDocuments()
+	matches := make([]match, len(ids))
+	for i, id := range ids {
+		matches[i] = match{id: id}
+	}
+	return matches
+}
+
+func applyFilters(s postingsSource, matches []match, filters []filter) []match {
+	result := make([]match, 0)
+	for _, m := range matches {
+		doc, ok := s.document(m.id)
+		if !ok {
+			continue
+		}
+		accepted := true
+		for _, f := range filters {
+			if !f.accepts(doc) {
+				accepted = false
+				break
+			}
+		}
+		if accepted {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// intersect returns matches that are present in both lists. Lists must be
+// sorted by DocID. The shorter list is walked through, while positions in the
+// longer one are found by galloping, so intersecting a short list with a long
+// one doesn't require looking at every element of the long one.
+func intersect(a, b []match) []match {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	result := make([]match, 0, len(a))
+	j := 0
+	for _, m := range a {
+		j = gallop(b, j, m.id)
+		if j == len(b) {
+			break
+		}
+		if b[j].id == m.id {
+			result = append(result, mergeMatches(m, b[j]))
+			j++
+		}
+	}
+	return result
+}
+
+// difference returns matches from a that are not in b.
+func difference(a, b []match) []match {
+	result := make([]match, 0, len(a))
+	j := 0
+	for _, m := range a {
+		j = gallop(b, j, m.id)
+		if j == len(b) || b[j].id != m.id {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// gallop returns index of the first match in list[from:] with DocID that is
+// not less than id. It checks elements at exponentially growing distances and
+// then does a binary search within the last interval.
+func gallop(list []match, from int, id DocID) int {
+	bound := 1
+	for from+bound < len(list) && list[from+bound].id < id {
+		bound *= 2
+	}
+	lo := from + bound/2
+	hi := from + bound + 1
+	if hi > len(list) {
+		hi = len(list)
+	}
+	if lo > hi {
+		lo = hi
+	}
+	return lo + sort.Search(hi-lo, func(i int) bool { return list[lo+i].id >= id })
+}
+
+// union merges two lists of matches sorted by DocID.
+func union(a, b []match) []match {
+	result := make([]match, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].id < b[j].id:
+			result = append(result, a[i])
+			i++
+		case a[i].id > b[j].id:
+			result = append(result, b[j])
+			j++
+		default:
+			result = append(result, mergeMatches(a[i], b[j]))
+			i++
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+	return append(result, b[j:]...)
+}
+
+func mergeMatches(a, b match) match {
+	m := match{id: a.id, postings: make(map[Keyword]Posting, len(a.postings)+len(b.postings))}
+	for k, p := range a.postings {
+		m.postings[k] = p
+	}
+	for k, p := range b.postings {
+		m.postings[k] = p
+	}
+	return m
+}
@@ -0,0 +1,371 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"go.roman.zone/crawl/analysis"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrEmptyQuery = errors.New("query is empty")
+)
+
+// ParseQuery parses a search query. The syntax is:
+//
+//	go crawler              both keywords (AND is implied)
+//	go AND crawler          same as above
+//	go OR rust              any of the keywords; commas work the same way
+//	NOT java, -java         exclude documents with a keyword
+//	(go OR rust) crawler    grouping
+//	"web crawler"           phrase
+//	"web crawler"~3         keywords within 3 other keywords from each other
+//	site:example.com        documents from a host and its subdomains
+//	lang:en                 documents in a language
+//	type:text/html          documents with a MIME type, type:text for any text
+//	after:2024-01-31        documents fetched on or after a date
+//	before:2024-01-31       documents fetched before a date
+//	title:crawler           keyword in a field (see Fields)
+//	title:"web crawler"     phrase in a field
+//	crawl*                  keywords that start with "crawl"
+//	cr?wl*                  wildcards: ? is one character, * is any number
+//	crawlr~1                keywords within 1 edit from "crawlr" (2 if omitted)
+//
+// Operators need to be in upper case. NOT binds tighter than AND, which binds
+// tighter than OR. Words are split into keywords with the analyzer, which
+// needs to be the analyzer of the index that is searched.
+func ParseQuery(q string, analyzer analysis.Analyzer) (Query, error) {
+	tokens, err := lexQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, analyzer: analyzer}
+	query, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in query", p.tokens[p.pos].value)
+	}
+	if query == nil {
+		return nil, ErrEmptyQuery
+	}
+	return query, nil
+}
+
+type queryTokenType int
+
+const (
+	queryWord queryTokenType = iota
+	queryPhrase
+	queryAnd
+	queryOr
+	queryNot
+	queryOpen
+	queryClose
+)
+
+type queryToken struct {
+	kind  queryTokenType
+	value string
+	// Field prefix like "title" in title:word
+	field string
+	// Proximity of a phrase
+	slop int
+}
+
+func lexQuery(q string) ([]queryToken, error) {
+	tokens := make([]queryToken, 0)
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == ',':
+			tokens = append(tokens, queryToken{kind: queryOr, value: ","})
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: queryOpen, value: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: queryClose, value: ")"})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, queryToken{kind: queryNot, value: "-"})
+			i++
+		case r == '"':
+			t, end, err := lexPhrase(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i = end
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`,()"`, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+			switch word {
+			case "AND":
+				tokens = append(tokens, queryToken{kind: queryAnd, value: word})
+				continue
+			case "OR":
+				tokens = append(tokens, queryToken{kind: queryOr, value: word})
+				continue
+			case "NOT":
+				tokens = append(tokens, queryToken{kind: queryNot, value: word})
+				continue
+			}
+			field := ""
+			if colon := strings.Index(word, ":"); colon > 0 && isField(word[:colon]) {
+				field, word = strings.ToLower(word[:colon]), word[colon+1:]
+				// Field can be followed by a phrase
+				if word == "" && i < len(runes) && runes[i] == '"' {
+					t, end, err := lexPhrase(runes, i)
+					if err != nil {
+						return nil, err
+					}
+					t.field = field
+					tokens = append(tokens, t)
+					i = end
+					continue
+				}
+			}
+			tokens = append(tokens, queryToken{kind: queryWord, value: word, field: field})
+		}
+	}
+	return tokens, nil
+}
+
+// lexPhrase reads a phrase in quotes with optional proximity suffix, starting
+// from the opening quote.
+func lexPhrase(runes []rune, start int) (queryToken, int, error) {
+	end := start + 1
+	for end < len(runes) && runes[end] != '"' {
+		end++
+	}
+	if end == len(runes) {
+		return queryToken{}, 0, errors.New("unterminated quote in query")
+	}
+	t := queryToken{kind: queryPhrase, value: string(runes[start+1 : end])}
+	end++
+	if end < len(runes) && runes[end] == '~' {
+		numEnd := end + 1
+		for numEnd < len(runes) && unicode.IsDigit(runes[numEnd]) {
+			numEnd++
+		}
+		slop, err := strconv.Atoi(string(runes[end+1 : numEnd]))
+		if err != nil {
+			return queryToken{}, 0, fmt.Errorf("invalid proximity %q", string(runes[end:numEnd]))
+		}
+		t.slop = slop
+		end = numEnd
+	}
+	return t, end, nil
+}
+
+// Fields that filter documents by their attributes instead of keywords
+var filterFields = []string{"site", "lang", "type", "after", "before"}
+
+func isField(name string) bool {
+	_, ok := ParseField(name)
+	return ok || isFilterField(strings.ToLower(name))
+}
+
+func isFilterField(name string) bool {
+	for _, f := range filterFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+type queryParser struct {
+	tokens   []queryToken
+	pos      int
+	analyzer analysis.Analyzer
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// Parsing functions return nil queries for parts that don't contain any
+// keywords, like punctuation.
+
+func (p *queryParser) parseOr() (Query, error) {
+	operands := make(Or, 0)
+	for {
+		operand, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if operand != nil {
+			operands = append(operands, operand)
+		}
+		if t, ok := p.peek(); !ok || t.kind != queryOr {
+			break
+		}
+		p.pos++
+	}
+	switch len(operands) {
+	case 0:
+		return nil, nil
+	case 1:
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *queryParser) parseAnd() (Query, error) {
+	operands := make(And, 0)
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == queryOr || t.kind == queryClose {
+			break
+		}
+		if t.kind == queryAnd {
+			p.pos++
+			continue
+		}
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if operand != nil {
+			operands = append(operands, operand)
+		}
+	}
+	switch len(operands) {
+	case 0:
+		return nil, nil
+	case 1:
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *queryParser) parseNot() (Query, error) {
+	t, _ := p.peek()
+	if t.kind != queryNot {
+		return p.parsePrimary()
+	}
+	p.pos++
+	operand, err := p.parseNot()
+	if err != nil || operand == nil {
+		return nil, err
+	}
+	return Not{operand}, nil
+}
+
+func (p *queryParser) parsePrimary() (Query, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, errors.New("unexpected end of query")
+	}
+	p.pos++
+	switch t.kind {
+	case queryOpen:
+		query, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.peek(); !ok || t.kind != queryClose {
+			return nil, errors.New("missing closing parenthesis in query")
+		}
+		p.pos++
+		return query, nil
+	case queryPhrase:
+		return fieldQuery(t.field, Keywords(p.analyzer, t.value), t.slop), nil
+	case queryWord:
+		if isFilterField(t.field) {
+			return filterQuery(t.field, t.value)
+		}
+		return p.wordQuery(t.field, t.value)
+	default:
+		return nil, fmt.Errorf("unexpected %q in query", t.value)
+	}
+}
+
+// filterQuery creates a filter for one of filterFields.
+func filterQuery(field, value string) (Query, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%s: requires a value", field)
+	}
+	switch field {
+	case "lang":
+		return Language{Code: value}, nil
+	case "type":
+		return ContentType{Type: value}, nil
+	case "after", "before":
+		t, err := ParseTime(value)
+		if err != nil {
+			return nil, err
+		}
+		if field == "after" {
+			return FetchedBetween{From: t}, nil
+		}
+		return FetchedBetween{To: t}, nil
+	}
+	return Site{Host: value}, nil
+}
+
+// wordQuery creates a query for a word that can also be a prefix, a pattern
+// with wildcards or a fuzzy keyword.
+func (p *queryParser) wordQuery(field string, word string) (Query, error) {
+	f, _ := ParseField(field)
+	if i := strings.LastIndex(word, "~"); i > 0 {
+		distance := MAX_EDIT_DISTANCE
+		if i+1 < len(word) {
+			d, err := strconv.Atoi(word[i+1:])
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("invalid edit distance %q", word[i:])
+			}
+			distance = d
+		}
+		keywords := Keywords(p.analyzer, word[:i])
+		if len(keywords) != 1 {
+			return nil, fmt.Errorf("fuzzy query %q needs a single keyword", word)
+		}
+		return Fuzzy{Field: f, Keyword: keywords[0], Distance: distance}, nil
+	}
+	// Question marks at the end are punctuation rather than wildcards
+	pattern := strings.TrimRight(word, string(WILDCARD_ONE))
+	wildcards := string([]rune{WILDCARD_ANY, WILDCARD_ONE})
+	if !strings.ContainsAny(pattern, wildcards) {
+		return fieldQuery(field, Keywords(p.analyzer, word), 0), nil
+	}
+	pattern = foldPattern(pattern)
+	if strings.Trim(pattern, wildcards) == "" {
+		return nil, fmt.Errorf("pattern %q needs at least one character", word)
+	}
+	if i := strings.IndexAny(pattern, wildcards); i == len(pattern)-1 && pattern[i] == WILDCARD_ANY {
+		return Prefix{Field: f, Prefix: Keyword(pattern[:i])}, nil
+	}
+	return Wildcard{Field: f, Pattern: pattern}, nil
+}
+
+// fieldQuery creates a query for keywords in a field. Empty field means any
+// field for single keywords and body for phrases.
+func fieldQuery(field string, keywords []Keyword, slop int) Query {
+	if len(keywords) == 0 {
+		return nil
+	}
+	f, _ := ParseField(field)
+	if len(keywords) > 1 {
+		return Phrase{Keywords: keywords, Slop: slop, Field: f}
+	}
+	if field == "" {
+		return Term{Keyword: keywords[0]}
+	}
+	return FieldTerm{Field: f, Keyword: keywords[0]}
+}
@@ -0,0 +1,104 @@
+package index
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	i := NewMemoryIndex(analysis.Default)
+	pages := []Page{
+		{URL: url.URL{Scheme: "https", Host: "golang.org", Path: "/crawler"}, Title: "Go crawler", Content: "A web crawler written in Go",
+			Language: "en", ContentType: "text/html", FetchTime: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{URL: url.URL{Scheme: "https", Host: "blog.rust-lang.org"}, Title: "Rust", Content: "A web crawler written in Rust", Anchors: []string{"Rust blog"},
+			Language: "en", ContentType: "text/plain", FetchTime: time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)},
+		{URL: url.URL{Scheme: "https", Host: "java.com"}, Title: "Java", Content: "Java web server", Headings: []string{"Java web server"},
+			Language: "de", ContentType: "text/html", FetchTime: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, page := range pages {
+		if err := i.Add(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := map[string][]string{
+		"crawler":                    {"blog.rust-lang.org", "golang.org"},
+		"web -crawler":               {"java.com"},
+		"web NOT rust":               {"golang.org", "java.com"},
+		"(go OR java) web":           {"golang.org", "java.com"},
+		"go, java":                   {"golang.org", "java.com"},
+		`"written in rust"`:          {"blog.rust-lang.org"},
+		`"crawler go"~3`:             {"golang.org"},
+		"crawler site:rust-lang.org": {"blog.rust-lang.org"},
+		"title:java":                 {"java.com"},
+		"url:crawler":                {"golang.org"},
+		"-web":                       {},
+		"crawler AND NOT title:rust": {"golang.org"},
+		`title:"go crawler"`:         {"golang.org"},
+		"headings:server":            {"java.com"},
+		"anchor:rust":                {"blog.rust-lang.org"},
+		"body:java":                  {"java.com"},
+		"crawl*":                     {"blog.rust-lang.org", "golang.org"},
+		"title:ja*":                  {"java.com"},
+		"w?b serv*":                  {"java.com"},
+		"crawlr~1":                   {"blog.rust-lang.org", "golang.org"},
+		"jav~":                       {"java.com"},
+		"rust?":                      {"blog.rust-lang.org"},
+		"web lang:de":                {"java.com"},
+		"lang:EN -rust":              {"golang.org"},
+		"type:text/html":             {"golang.org", "java.com"},
+		"type:text crawler":          {"blog.rust-lang.org", "golang.org"},
+		"after:2024-02-10":           {"blog.rust-lang.org", "java.com"},
+		"web before:2024-02-10":      {"golang.org"},
+		"after:2024-01-01T00:00:00Z before:2024-03-01": {"blog.rust-lang.org", "golang.org"},
+	}
+	for q, expected := range cases {
+		query, err := ParseQuery(q, analysis.Default)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %s", q, err)
+			continue
+		}
+		hits, err := i.Search(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hosts := make([]string, len(hits))
+		for n, hit := range hits {
+			hosts[n] = hit.Document.URL.Host
+		}
+		sort.Strings(hosts)
+		if len(hosts) != len(expected) {
+			t.Errorf("Expected %v for %q, got %v", expected, q, hosts)
+			continue
+		}
+		for n := range hosts {
+			if hosts[n] != expected[n] {
+				t.Errorf("Expected %v for %q, got %v", expected, q, hosts)
+				break
+			}
+		}
+	}
+
+	for _, q := range []string{"", `"unterminated`, "(go", "go)", "*", "crawler~x", "lang:", "after:yesterday"} {
+		if _, err := ParseQuery(q, analysis.Default); err == nil {
+			t.Errorf("Expected an error for %q", q)
+		}
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	a := make([]match, 0)
+	for id := DocID(0); id < 1000; id++ {
+		a = append(a, match{id: id})
+	}
+	b := []match{{id: 3}, {id: 500}, {id: 999}, {id: 2000}}
+	result := intersect(a, b)
+	if len(result) != 3 || result[0].id != 3 || result[1].id != 500 || result[2].id != 999 {
+		t.Errorf("Unexpected intersection %v", result)
+	}
+	if d := difference(b, a); len(d) != 1 || d[0].id != 2000 {
+		t.Errorf("Unexpected difference %v", d)
+	}
+}
@@ -0,0 +1,170 @@
+// Package ranking scores documents found in the index by their relevance to a
+// query.
+package ranking
+
+import (
+	"fmt"
+	"go.roman.zone/crawl/index"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DEFAULT_K1 = 1.2
+	DEFAULT_B  = 0.75
+)
+
+// DefaultBoosts returns weights of fields that are used by NewBM25.
+func DefaultBoosts() map[index.Field]float64 {
+	return map[index.Field]float64{
+		index.FIELD_BODY:     1,
+		index.FIELD_TITLE:    3,
+		index.FIELD_HEADINGS: 2,
+		index.FIELD_URL:      1.5,
+		index.FIELD_ANCHOR:   2,
+	}
+}
+
+// Collection provides statistics about indexed documents.
+type Collection interface {
+	Stats() index.Stats
+	DocumentFrequency(keyword index.Keyword) (int, error)
+}
+
+// Result is a scored document.
+type Result struct {
+	Document index.Document
+	Score    float64
+	// Keywords that matched in any of the fields, sorted
+	Keywords []index.Keyword
+}
+
+// BM25 implements the Okapi BM25 ranking function.
+type BM25 struct {
+	// Controls how quickly the score saturates as the frequency of a keyword
+	// in a document grows.
+	K1 float64
+	// Controls how much the score is normalized by the length of a document
+	// (0 means no normalization, 1 means full normalization).
+	B float64
+	// Weights of keyword occurrences in each field. Fields that are not
+	// listed have a weight of 1.
+	Boosts map[index.Field]float64
+}
+
+func NewBM25() BM25 {
+	return BM25{K1: DEFAULT_K1, B: DEFAULT_B, Boosts: DefaultBoosts()}
+}
+
+// ParseBoosts parses a comma-separated list of fields with weights like
+// "title:3,url:2".
+func ParseBoosts(s string) (map[index.Field]float64, error) {
+	boosts := make(map[index.Field]float64)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		i := strings.LastIndex(item, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("missing weight for field %q", item)
+		}
+		field, ok := index.ParseField(item[:i])
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", item[:i])
+		}
+		boost, err := strconv.ParseFloat(item[i+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for field %q: %s", field, err)
+		}
+		boosts[field] = boost
+	}
+	return boosts, nil
+}
+
+// Rank scores hits and returns them sorted by score, highest first.
+//
+// Frequencies of a keyword in all fields are added up with their boosts and
+// then used as a single frequency, which is then normalized by the length of
+// the body.
+func (r BM25) Rank(hits []index.Hit, c Collection) ([]Result, error) {
+	results, err := r.score(hits, c)
+	if err != nil {
+		return nil, err
+	}
+	sortResults(results)
+	return results, nil
+}
+
+// sortResults sorts results by score, highest first. Results with equal
+// scores keep their order.
+func sortResults(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+}
+
+// RankTop is like Rank, but only returns k results with the highest scores.
+// It's faster than Rank when there are many more hits than k.
+func (r BM25) RankTop(hits []index.Hit, c Collection, k int) ([]Result, error) {
+	results, err := r.score(hits, c)
+	if err != nil {
+		return nil, err
+	}
+	return Top(results, k), nil
+}
+
+func (r BM25) score(hits []index.Hit, c Collection) ([]Result, error) {
+	stats := c.Stats()
+	avgLength := stats.AverageLength()
+
+	idf := make(map[index.Keyword]float64)
+	results := make([]Result, len(hits))
+	for i, hit := range hits {
+		frequencies := make(map[index.Keyword]float64)
+		for fieldKeyword, p := range hit.Postings {
+			field, keyword := index.SplitFieldKeyword(fieldKeyword)
+			frequencies[keyword] += r.boost(field) * float64(p.Frequency)
+		}
+		score := 0.0
+		keywords := make([]index.Keyword, 0, len(frequencies))
+		for keyword, frequency := range frequencies {
+			keywords = append(keywords, keyword)
+			keywordIDF, ok := idf[keyword]
+			if !ok {
+				df, err := c.DocumentFrequency(keyword)
+				if err != nil {
+					return nil, err
+				}
+				keywordIDF = r.idf(stats.Documents, df)
+				idf[keyword] = keywordIDF
+			}
+			score += keywordIDF * r.tf(frequency, hit.Document.Length, avgLength)
+		}
+		sort.Slice(keywords, func(i, j int) bool { return keywords[i] < keywords[j] })
+		results[i] = Result{Document: hit.Document, Score: score, Keywords: keywords}
+	}
+	return results, nil
+}
+
+// idf is the inverse document frequency with a correction that keeps it
+// positive for keywords that appear in most documents.
+func (r BM25) idf(documents, df int) float64 {
+	return math.Log(1 + (float64(documents-df)+0.5)/(float64(df)+0.5))
+}
+
+// tf is the normalized keyword frequency component of the score.
+func (r BM25) tf(frequency float64, length int, avgLength float64) float64 {
+	norm := 1.0
+	if avgLength > 0 {
+		norm = 1 - r.B + r.B*float64(length)/avgLength
+	}
+	return frequency * (r.K1 + 1) / (frequency + r.K1*norm)
+}
+
+func (r BM25) boost(field index.Field) float64 {
+	if boost, ok := r.Boosts[field]; ok {
+		return boost
+	}
+	return 1
+}
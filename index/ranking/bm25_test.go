@@ -0,0 +1,53 @@
+package ranking
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"go.roman.zone/crawl/index"
+	"net/url"
+	"testing"
+)
+
+func TestBM25(t *testing.T) {
+	idx := index.NewMemoryIndex(analysis.Default)
+	pages := map[string]string{
+		"short": "crawler written in go",
+		"long":  "a very long page that mentions crawler once and then talks about many other things for a while",
+		"other": "nothing relevant here",
+	}
+	for host, content := range pages {
+		if err := idx.Add(index.Page{URL: url.URL{Scheme: "https", Host: host}, Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hits, err := idx.Search(index.Term{Keyword: "crawler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := NewBM25().Rank(hits, idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Document.URL.Host != "short" || results[0].Score <= results[1].Score {
+		t.Errorf("Expected the shorter page to rank higher: %+v", results)
+	}
+
+	// Same page with the keyword in the title
+	page := index.Page{URL: url.URL{Scheme: "https", Host: "long"}, Title: "Crawler", Content: pages["long"]}
+	if err := idx.Add(page); err != nil {
+		t.Fatal(err)
+	}
+	hits, err = idx.Search(index.Term{Keyword: "crawler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err = NewBM25().Rank(hits, idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Document.URL.Host != "long" {
+		t.Errorf("Expected the page with a title match to rank higher: %+v", results)
+	}
+}
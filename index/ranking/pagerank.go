@@ -0,0 +1,140 @@
+package ranking
+
+import (
+	"go.roman.zone/crawl/index"
+	"math"
+	"sort"
+)
+
+const (
+	DEFAULT_DAMPING    = 0.85
+	DEFAULT_ITERATIONS = 100
+	// Iterations stop once the scores change by less than this in total
+	DEFAULT_TOLERANCE = 1e-9
+
+	DEFAULT_PAGERANK_WEIGHT = 0.5
+)
+
+// PageRank computes PageRank of pages in a link graph, which is a map of page
+// URLs to URLs of pages they link to. Pages that only appear as links are
+// included too. Rank of pages without links is spread across all pages.
+//
+// Scores are relative: they are multiplied by the number of pages, so that
+// they are 1 on average and don't depend on the size of the graph.
+func PageRank(links map[string][]string, damping float64, iterations int, tolerance float64) map[string]float64 {
+	ids := make(map[string]int)
+	pages := make([]string, 0)
+	// Links of each page by their indexes in pages
+	outlinks := make([][]int, 0)
+	id := func(page string) int {
+		n, ok := ids[page]
+		if !ok {
+			n = len(pages)
+			ids[page] = n
+			pages = append(pages, page)
+			outlinks = append(outlinks, nil)
+		}
+		return n
+	}
+	// Sources are sorted, so that results don't depend on the order of the map
+	sources := make([]string, 0, len(links))
+	for source := range links {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		s := id(source)
+		for _, target := range links[source] {
+			t := id(target)
+			outlinks[s] = append(outlinks[s], t)
+		}
+	}
+
+	count := float64(len(pages))
+	rank := make([]float64, len(pages))
+	for n := range rank {
+		rank[n] = 1 / count
+	}
+	next := make([]float64, len(pages))
+	for i := 0; i < iterations; i++ {
+		dangling := 0.0
+		for n := range rank {
+			if len(outlinks[n]) == 0 {
+				dangling += rank[n]
+			}
+		}
+		base := (1-damping)/count + damping*dangling/count
+		for n := range next {
+			next[n] = base
+		}
+		for s, targets := range outlinks {
+			share := damping * rank[s] / float64(len(targets))
+			for _, t := range targets {
+				next[t] += share
+			}
+		}
+		change := 0.0
+		for n := range rank {
+			change += math.Abs(next[n] - rank[n])
+		}
+		rank, next = next, rank
+		if change < tolerance {
+			break
+		}
+	}
+
+	scores := make(map[string]float64, len(pages))
+	for n, page := range pages {
+		scores[page] = rank[n] * count
+	}
+	return scores
+}
+
+// PageRankBlend combines relevance with PageRank of documents. Relevance
+// scores are multiplied by
+//
+//	1 + Weight * ln(1 + PageRank)
+//
+// where PageRank is relative, as returned by PageRank. Scores of documents
+// without PageRank are left as they are.
+type PageRankBlend struct {
+	Relevance BM25
+	// PageRank of pages by their URLs
+	PageRank map[string]float64
+	Weight   float64
+}
+
+// Rank scores hits and returns them sorted by score, highest first.
+func (b PageRankBlend) Rank(hits []index.Hit, c Collection) ([]Result, error) {
+	results, err := b.score(hits, c)
+	if err != nil {
+		return nil, err
+	}
+	sortResults(results)
+	return results, nil
+}
+
+// RankTop is like Rank, but only returns k results with the highest scores.
+func (b PageRankBlend) RankTop(hits []index.Hit, c Collection, k int) ([]Result, error) {
+	results, err := b.score(hits, c)
+	if err != nil {
+		return nil, err
+	}
+	return Top(results, k), nil
+}
+
+func (b PageRankBlend) score(hits []index.Hit, c Collection) ([]Result, error) {
+	results, err := b.Relevance.score(hits, c)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.PageRank) == 0 || b.Weight == 0 {
+		return results, nil
+	}
+	for i := range results {
+		if pr, ok := b.PageRank[results[i].Document.URL.String()]; ok {
+			results[i].Score *= 1 + b.Weight*math.Log1p(pr)
+		}
+	}
+	return results, nil
+}
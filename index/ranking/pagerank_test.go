@@ -0,0 +1,59 @@
+package ranking
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"go.roman.zone/crawl/index"
+	"math"
+	"net/url"
+	"testing"
+)
+
+func TestPageRank(t *testing.T) {
+	cycle := PageRank(map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}}, DEFAULT_DAMPING, DEFAULT_ITERATIONS, DEFAULT_TOLERANCE)
+	for page, score := range cycle {
+		if math.Abs(score-1) > 1e-6 {
+			t.Errorf("Expected 1 for %q in a cycle, got %f", page, score)
+		}
+	}
+
+	// "d" doesn't link anywhere, but it's in the graph
+	star := PageRank(map[string][]string{"a": {"d"}, "b": {"d"}, "c": {"d", "a"}}, DEFAULT_DAMPING, DEFAULT_ITERATIONS, DEFAULT_TOLERANCE)
+	if len(star) != 4 {
+		t.Fatalf("Expected 4 pages, got %v", star)
+	}
+	sum := 0.0
+	for _, score := range star {
+		sum += score
+	}
+	if math.Abs(sum-4) > 1e-6 {
+		t.Errorf("Expected scores to add up to 4, got %f", sum)
+	}
+	if !(star["d"] > star["a"] && star["a"] > star["b"] && math.Abs(star["b"]-star["c"]) < 1e-9) {
+		t.Errorf("Unexpected scores %v", star)
+	}
+}
+
+func TestPageRankBlend(t *testing.T) {
+	idx := index.NewMemoryIndex(analysis.Default)
+	for _, host := range []string{"a", "b"} {
+		if err := idx.Add(index.Page{URL: url.URL{Scheme: "https", Host: host}, Content: "web crawler"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hits, err := idx.Search(index.Term{Keyword: "crawler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blend := PageRankBlend{
+		Relevance: NewBM25(),
+		PageRank:  map[string]float64{"https://a": 0.5, "https://b": 1.5},
+		Weight:    DEFAULT_PAGERANK_WEIGHT,
+	}
+	results, err := blend.Rank(hits, idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Document.URL.Host != "b" || results[0].Score <= results[1].Score {
+		t.Errorf("Expected the page with higher PageRank first, got %+v", results)
+	}
+}
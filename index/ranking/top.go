@@ -0,0 +1,65 @@
+package ranking
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Top returns k results with the highest scores, sorted by score. Results
+// with equal scores keep their order, like with Rank. It keeps a heap of k
+// results instead of sorting all of them.
+func Top(results []Result, k int) []Result {
+	if k > len(results) {
+		k = len(results)
+	}
+	if k <= 0 {
+		return []Result{}
+	}
+	h := make(resultHeap, 0, k)
+	for i, r := range results {
+		if len(h) < k {
+			heap.Push(&h, rankedResult{r, i})
+		} else if r.Score > h[0].Score {
+			// A later result only wins with a strictly higher score
+			h[0] = rankedResult{r, i}
+			heap.Fix(&h, 0)
+		}
+	}
+	sort.Sort(sort.Reverse(h))
+	top := make([]Result, len(h))
+	for i, r := range h {
+		top[i] = r.Result
+	}
+	return top
+}
+
+type rankedResult struct {
+	Result
+	// Position in the original results, which breaks ties
+	position int
+}
+
+// resultHeap is a min-heap of results, so that the worst of the top results
+// can be replaced quickly.
+type resultHeap []rankedResult
+
+func (h resultHeap) Len() int { return len(h) }
+
+// Less reports whether result i is worse than result j.
+func (h resultHeap) Less(i, j int) bool {
+	if h[i].Score != h[j].Score {
+		return h[i].Score < h[j].Score
+	}
+	return h[i].position > h[j].position
+}
+
+func (h resultHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *resultHeap) Push(x interface{}) { *h = append(*h, x.(rankedResult)) }
+
+func (h *resultHeap) Pop() interface{} {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
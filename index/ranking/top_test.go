@@ -0,0 +1,34 @@
+package ranking
+
+import (
+	"go.roman.zone/crawl/index"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestTop(t *testing.T) {
+	results := make([]Result, 100)
+	for i := range results {
+		// Scores repeat, so that ties are checked too
+		results[i] = Result{Document: index.Document{ID: index.DocID(i)}, Score: float64(rand.Intn(20))}
+	}
+	sorted := append([]Result(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+
+	for _, k := range []int{0, 1, 10, 100, 200} {
+		top := Top(results, k)
+		expected := k
+		if expected > len(results) {
+			expected = len(results)
+		}
+		if len(top) != expected {
+			t.Fatalf("Expected %d results for k=%d, got %d", expected, k, len(top))
+		}
+		for i := range top {
+			if top[i].Document.ID != sorted[i].Document.ID {
+				t.Errorf("Expected document %d at %d for k=%d, got %d", sorted[i].Document.ID, i, k, top[i].Document.ID)
+			}
+		}
+	}
+}
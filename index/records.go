@@ -0,0 +1,115 @@
+package index
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+)
+
+const recordHeaderSize = 4 + 4
+
+// recordFile is an append-only file of records. Each record is written as:
+//
+//	payload length (uint32), CRC-32 of the payload (uint32), payload
+//
+// The file is synced after every append. A record that was only partially
+// written when the program crashed fails the checksum and is dropped along
+// with everything after it.
+type recordFile struct {
+	file *os.File
+}
+
+// openRecordFile opens a file of records and calls apply for the payload of
+// every record in it. Payloads that apply can't decode are treated like
+// corrupt records.
+func openRecordFile(path string, apply func(payload []byte) bool) (*recordFile, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	valid := readRecords(data, apply)
+	if valid < len(data) {
+		log.Printf("Dropping %d bytes of corrupt records at the end of %s", len(data)-valid, path)
+		err = f.Truncate(int64(valid))
+	}
+	if err == nil {
+		_, err = f.Seek(int64(valid), io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &recordFile{file: f}, nil
+}
+
+// readRecords calls apply for payloads of records in data and returns the
+// size of valid records.
+func readRecords(data []byte, apply func(payload []byte) bool) int {
+	valid := 0
+	for valid < len(data) {
+		payload, size, ok := readRecord(data[valid:])
+		if !ok || !apply(payload) {
+			break
+		}
+		valid += size
+	}
+	return valid
+}
+
+// readRecord reads a record from the beginning of data and returns its
+// payload and size. The record is invalid if it's incomplete or doesn't match
+// its checksum.
+func readRecord(data []byte) ([]byte, int, bool) {
+	if len(data) < recordHeaderSize {
+		return nil, 0, false
+	}
+	length := int(binary.LittleEndian.Uint32(data))
+	if length < 1 || length > len(data)-recordHeaderSize {
+		return nil, 0, false
+	}
+	payload := data[recordHeaderSize : recordHeaderSize+length]
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(data[4:]) {
+		return nil, 0, false
+	}
+	return payload, recordHeaderSize + length, true
+}
+
+func appendRecord(b []byte, payload []byte) []byte {
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(payload)))
+	b = binary.LittleEndian.AppendUint32(b, crc32.ChecksumIEEE(payload))
+	return append(b, payload...)
+}
+
+// append writes records with the payloads and syncs the file.
+func (f *recordFile) append(payloads ...[]byte) error {
+	data := make([]byte, 0)
+	for _, p := range payloads {
+		data = appendRecord(data, p)
+	}
+	if _, err := f.file.Write(data); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+// reset removes all records.
+func (f *recordFile) reset() error {
+	if err := f.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+func (f *recordFile) close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,502 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Segments are immutable files that store a part of the index. Every segment
+// contains a document store, postings and a term dictionary:
+//
+//	header        magic (8 bytes), format version (1 byte)
+//	documents     document records
+//	texts         compressed text of each document, prefixed with its length
+//	document table  for each document: ID (uint32), record offset (uint64),
+//	              text offset (uint64)
+//	postings      postings lists of all terms
+//	term table    for each term: term offset (uint32), term length (uint32),
+//	              postings offset (uint64), document frequency (uint32)
+//	terms         all terms, one after another
+//	footer        document table offset, document count, term table offset,
+//	              term count, terms offset (uint64 each), magic (8 bytes)
+//
+// Document table is sorted by ID and term table is sorted by term, so that
+// lookups can be done with a binary search directly on the file contents.
+//
+// Postings lists are stored as the number of postings followed by postings
+// where document IDs and positions are delta-encoded and written as varints.
+//
+// Fixed size integers are little-endian.
+
+const (
+	SEGMENT_MAGIC   = "CRAWLSEG"
+	SEGMENT_VERSION = 3
+
+	segmentHeaderSize  = len(SEGMENT_MAGIC) + 1
+	segmentFooterSize  = 5*8 + len(SEGMENT_MAGIC)
+	docTableEntrySize  = 4 + 8 + 8
+	termTableEntrySize = 4 + 4 + 8 + 4
+)
+
+var (
+	ErrCorruptSegment = errors.New("segment file is corrupt")
+)
+
+// segment is an open segment file.
+type segment struct {
+	name string
+	data []byte
+	// Releases the data
+	release func() error
+
+	docTable  []byte
+	docCount  int
+	termTable []byte
+	termCount int
+	terms     []byte
+}
+
+func openSegment(path, name string) (*segment, error) {
+	data, release, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := parseSegment(name, data)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	s.release = release
+	return s, nil
+}
+
+func parseSegment(name string, data []byte) (*segment, error) {
+	if len(data) < segmentHeaderSize+segmentFooterSize ||
+		string(data[:len(SEGMENT_MAGIC)]) != SEGMENT_MAGIC ||
+		string(data[len(data)-len(SEGMENT_MAGIC):]) != SEGMENT_MAGIC {
+		return nil, ErrCorruptSegment
+	}
+	if data[len(SEGMENT_MAGIC)] != SEGMENT_VERSION {
+		return nil, fmt.Errorf("%w: segment version %d, expected %d", ErrOldFormat, data[len(SEGMENT_MAGIC)], SEGMENT_VERSION)
+	}
+	footer := data[len(data)-segmentFooterSize:]
+	fields := make([]int, 5)
+	for n := range fields {
+		// Values larger than the file would overflow or become negative
+		// when converted to int
+		value := binary.LittleEndian.Uint64(footer[n*8:])
+		if value > uint64(len(data)) {
+			return nil, ErrCorruptSegment
+		}
+		fields[n] = int(value)
+	}
+	docTableOffset, docCount := fields[0], fields[1]
+	termTableOffset, termCount := fields[2], fields[3]
+	termsOffset := fields[4]
+	end := len(data) - segmentFooterSize
+	// Empty segments are never written, and segments need at least one
+	// document for their range of IDs
+	if docCount == 0 ||
+		docTableOffset+docCount*docTableEntrySize > termTableOffset ||
+		termTableOffset+termCount*termTableEntrySize != termsOffset || termsOffset > end {
+		return nil, ErrCorruptSegment
+	}
+	s := &segment{
+		name:      name,
+		data:      data,
+		docTable:  data[docTableOffset : docTableOffset+docCount*docTableEntrySize],
+		docCount:  docCount,
+		termTable: data[termTableOffset:termsOffset],
+		termCount: termCount,
+		terms:     data[termsOffset:end],
+	}
+	if err := s.checkTerms(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// checkTerms makes sure that entries of the term table point inside the file,
+// so that terms and postings can be read without checking them every time.
+func (s *segment) checkTerms() error {
+	for n := 0; n < s.termCount; n++ {
+		entry := s.termTable[n*termTableEntrySize:]
+		offset := uint64(binary.LittleEndian.Uint32(entry))
+		length := uint64(binary.LittleEndian.Uint32(entry[4:]))
+		postings := binary.LittleEndian.Uint64(entry[8:])
+		if offset+length > uint64(len(s.terms)) || postings >= uint64(len(s.data)) {
+			return ErrCorruptSegment
+		}
+	}
+	return nil
+}
+
+func (s *segment) close() error {
+	if s.release == nil {
+		return nil
+	}
+	return s.release()
+}
+
+func (s *segment) docIDAt(n int) DocID {
+	return DocID(binary.LittleEndian.Uint32(s.docTable[n*docTableEntrySize:]))
+}
+
+// minID and maxID return the range of document IDs in the segment.
+func (s *segment) minID() DocID { return s.docIDAt(0) }
+func (s *segment) maxID() DocID { return s.docIDAt(s.docCount - 1) }
+
+func (s *segment) document(id DocID) (Document, bool) {
+	n := sort.Search(s.docCount, func(n int) bool { return s.docIDAt(n) >= id })
+	if n == s.docCount || s.docIDAt(n) != id {
+		return Document{}, false
+	}
+	doc, err := s.documentAt(n)
+	return doc, err == nil
+}
+
+func (s *segment) documentAt(n int) (Document, error) {
+	offset := binary.LittleEndian.Uint64(s.docTable[n*docTableEntrySize+4:])
+	if offset >= uint64(len(s.data)) {
+		return Document{}, ErrCorruptSegment
+	}
+	doc, err := decodeDocument(&byteReader{data: s.data[offset:]})
+	doc.ID = s.docIDAt(n)
+	return doc, err
+}
+
+// compressedText returns compressed text of a document.
+func (s *segment) compressedText(id DocID) ([]byte, bool) {
+	n := sort.Search(s.docCount, func(n int) bool { return s.docIDAt(n) >= id })
+	if n == s.docCount || s.docIDAt(n) != id {
+		return nil, false
+	}
+	offset := binary.LittleEndian.Uint64(s.docTable[n*docTableEntrySize+12:])
+	if offset >= uint64(len(s.data)) {
+		return nil, false
+	}
+	r := &byteReader{data: s.data[offset:]}
+	length := r.uvarint()
+	if r.err != nil || length > uint64(len(r.data)) {
+		return nil, false
+	}
+	// Copying, so that the text can be used after the segment is closed
+	return append([]byte(nil), r.data[:length]...), true
+}
+
+// allIDs returns IDs of all documents in the segment in ascending order.
+func (s *segment) allIDs() []DocID {
+	ids := make([]DocID, s.docCount)
+	for n := range ids {
+		ids[n] = s.docIDAt(n)
+	}
+	return ids
+}
+
+// documents returns all documents in the segment in the order of their IDs.
+func (s *segment) documents() ([]Document, error) {
+	docs := make([]Document, s.docCount)
+	for n := range docs {
+		var err error
+		if docs[n], err = s.documentAt(n); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+func (s *segment) termAt(n int) Keyword {
+	entry := s.termTable[n*termTableEntrySize:]
+	offset := binary.LittleEndian.Uint32(entry)
+	length := binary.LittleEndian.Uint32(entry[4:])
+	return Keyword(s.terms[offset : offset+length])
+}
+
+// allTerms returns all terms of the segment in sorted order.
+func (s *segment) allTerms() []Keyword {
+	terms := make([]Keyword, s.termCount)
+	for n := range terms {
+		terms[n] = s.termAt(n)
+	}
+	return terms
+}
+
+// keywords returns terms of the segment that start with a prefix.
+func (s *segment) keywords(prefix Keyword) []Keyword {
+	return keywordsWithPrefix(s.termCount, s.termAt, prefix)
+}
+
+func (s *segment) findTerm(keyword Keyword) (int, bool) {
+	n := sort.Search(s.termCount, func(n int) bool { return s.termAt(n) >= keyword })
+	return n, n < s.termCount && s.termAt(n) == keyword
+}
+
+func (s *segment) docFreq(keyword Keyword) int {
+	n, ok := s.findTerm(keyword)
+	if !ok {
+		return 0
+	}
+	return s.docFreqAt(n)
+}
+
+func (s *segment) docFreqAt(n int) int {
+	return int(binary.LittleEndian.Uint32(s.termTable[n*termTableEntrySize+16:]))
+}
+
+// keywordCounts returns terms of the segment that start with a prefix along
+// with their document frequencies.
+func (s *segment) keywordCounts(prefix Keyword) []KeywordCount {
+	counts := make([]KeywordCount, 0)
+	n := sort.Search(s.termCount, func(n int) bool { return s.termAt(n) >= prefix })
+	for ; n < s.termCount; n++ {
+		k := s.termAt(n)
+		if !strings.HasPrefix(string(k), string(prefix)) {
+			break
+		}
+		counts = append(counts, KeywordCount{Keyword: k, Documents: s.docFreqAt(n)})
+	}
+	return counts
+}
+
+func (s *segment) postings(keyword Keyword) []Posting {
+	n, ok := s.findTerm(keyword)
+	if !ok {
+		return nil
+	}
+	offset := binary.LittleEndian.Uint64(s.termTable[n*termTableEntrySize+8:])
+	postings, err := decodePostings(&byteReader{data: s.data[offset:]})
+	if err != nil {
+		return nil
+	}
+	return postings
+}
+
+// writeSegment writes documents with their compressed texts and postings into
+// a new segment file. Documents must be sorted by ID and postings of each
+// keyword must be sorted by DocID.
+func writeSegment(path string, docs []Document, texts map[DocID][]byte, postings map[Keyword][]Posting) error {
+	var buf bytes.Buffer
+	buf.WriteString(SEGMENT_MAGIC)
+	buf.WriteByte(SEGMENT_VERSION)
+
+	docOffsets := make([]uint64, len(docs))
+	for n, doc := range docs {
+		docOffsets[n] = uint64(buf.Len())
+		buf.Write(encodeDocument(nil, doc))
+	}
+	textOffsets := make([]uint64, len(docs))
+	for n, doc := range docs {
+		textOffsets[n] = uint64(buf.Len())
+		buf.Write(binary.AppendUvarint(nil, uint64(len(texts[doc.ID]))))
+		buf.Write(texts[doc.ID])
+	}
+	docTableOffset := buf.Len()
+	for n, doc := range docs {
+		buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(doc.ID)))
+		buf.Write(binary.LittleEndian.AppendUint64(nil, docOffsets[n]))
+		buf.Write(binary.LittleEndian.AppendUint64(nil, textOffsets[n]))
+	}
+
+	terms := make([]Keyword, 0, len(postings))
+	for keyword := range postings {
+		terms = append(terms, keyword)
+	}
+	sort.Slice(terms, func(a, b int) bool { return terms[a] < terms[b] })
+	postingsOffsets := make([]uint64, len(terms))
+	for n, keyword := range terms {
+		postingsOffsets[n] = uint64(buf.Len())
+		buf.Write(encodePostings(nil, postings[keyword]))
+	}
+
+	termTableOffset := buf.Len()
+	termOffset := 0
+	for n, keyword := range terms {
+		buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(termOffset)))
+		buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(keyword))))
+		buf.Write(binary.LittleEndian.AppendUint64(nil, postingsOffsets[n]))
+		buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(postings[keyword]))))
+		termOffset += len(keyword)
+	}
+	termsOffset := buf.Len()
+	for _, keyword := range terms {
+		buf.WriteString(string(keyword))
+	}
+
+	for _, v := range []int{docTableOffset, len(docs), termTableOffset, len(terms), termsOffset} {
+		buf.Write(binary.LittleEndian.AppendUint64(nil, uint64(v)))
+	}
+	buf.WriteString(SEGMENT_MAGIC)
+
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+func encodePostings(b []byte, postings []Posting) []byte {
+	b = binary.AppendUvarint(b, uint64(len(postings)))
+	prevID := DocID(0)
+	for _, p := range postings {
+		b = binary.AppendUvarint(b, uint64(p.DocID-prevID))
+		prevID = p.DocID
+		b = binary.AppendUvarint(b, uint64(p.Frequency))
+		b = binary.AppendUvarint(b, uint64(len(p.Positions)))
+		prevPos := 0
+		for _, pos := range p.Positions {
+			b = binary.AppendUvarint(b, uint64(pos-prevPos))
+			prevPos = pos
+		}
+	}
+	return b
+}
+
+func decodePostings(r *byteReader) ([]Posting, error) {
+	count := r.uvarint()
+	if r.err != nil || count > uint64(len(r.data)) {
+		return nil, ErrCorruptSegment
+	}
+	postings := make([]Posting, count)
+	prevID := DocID(0)
+	for n := range postings {
+		p := &postings[n]
+		p.DocID = prevID + DocID(r.uvarint())
+		prevID = p.DocID
+		p.Frequency = int(r.uvarint())
+		positionCount := r.uvarint()
+		if r.err != nil || positionCount > uint64(len(r.data)) {
+			return nil, ErrCorruptSegment
+		}
+		p.Positions = make([]int, positionCount)
+		prevPos := 0
+		for i := range p.Positions {
+			p.Positions[i] = prevPos + int(r.uvarint())
+			prevPos = p.Positions[i]
+		}
+	}
+	return postings, r.err
+}
+
+func encodeDocument(b []byte, doc Document) []byte {
+	b = appendString(b, doc.URL.String())
+	b = appendString(b, doc.Title)
+	b = appendString(b, doc.Language)
+	b = appendString(b, doc.ContentType)
+	fetchTime := int64(0)
+	if !doc.FetchTime.IsZero() {
+		fetchTime = doc.FetchTime.UnixNano()
+	}
+	b = binary.AppendVarint(b, fetchTime)
+	b = binary.AppendUvarint(b, uint64(doc.Length))
+	b = appendString(b, doc.Hash)
+	b = binary.AppendUvarint(b, uint64(len(doc.Metadata)))
+	keys := make([]string, 0, len(doc.Metadata))
+	for k := range doc.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b = appendString(b, k)
+		b = appendString(b, doc.Metadata[k])
+	}
+	return b
+}
+
+func decodeDocument(r *byteReader) (Document, error) {
+	var doc Document
+	parsedURL, err := url.Parse(r.string())
+	if err != nil {
+		return doc, err
+	}
+	doc.URL = *parsedURL
+	doc.Title = r.string()
+	doc.Language = r.string()
+	doc.ContentType = r.string()
+	if fetchTime := r.varint(); fetchTime != 0 {
+		doc.FetchTime = time.Unix(0, fetchTime).UTC()
+	}
+	doc.Length = int(r.uvarint())
+	doc.Hash = r.string()
+	metadataCount := r.uvarint()
+	if r.err == nil && metadataCount > 0 {
+		if metadataCount > uint64(len(r.data)) {
+			return doc, ErrCorruptSegment
+		}
+		doc.Metadata = make(map[string]string, metadataCount)
+		for n := uint64(0); n < metadataCount; n++ {
+			k := r.string()
+			doc.Metadata[k] = r.string()
+		}
+	}
+	return doc, r.err
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// byteReader decodes values from a byte slice. After the first error all
+// reads return zero values and the error is kept in err.
+type byteReader struct {
+	data []byte
+	err  error
+}
+
+func (r *byteReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = ErrCorruptSegment
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *byteReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = ErrCorruptSegment
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *byteReader) string() string {
+	length := r.uvarint()
+	if r.err != nil {
+		return ""
+	}
+	if length > uint64(len(r.data)) {
+		r.err = ErrCorruptSegment
+		return ""
+	}
+	s := string(r.data[:length])
+	r.data = r.data[length:]
+	return s
+}
+
+func (r *byteReader) strings() []string {
+	count := r.uvarint()
+	if r.err != nil || count == 0 {
+		return nil
+	}
+	if count > uint64(len(r.data)) {
+		r.err = ErrCorruptSegment
+		return nil
+	}
+	list := make([]string, count)
+	for n := range list {
+		list[n] = r.string()
+	}
+	return list
+}
@@ -0,0 +1,215 @@
+// Package snippet makes short excerpts of documents that show where they
+// match a query.
+package snippet
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"go.roman.zone/crawl/index"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	DEFAULT_LENGTH    = 200 // bytes
+	DEFAULT_FRAGMENTS = 2
+
+	HIGHLIGHT_START = "<mark>"
+	HIGHLIGHT_END   = "</mark>"
+	ELLIPSIS        = "…"
+)
+
+type Options struct {
+	// Maximum length of each fragment
+	Length int
+	// Maximum number of fragments
+	Fragments int
+	// Analyzer of the index that keywords come from, analysis.Default if it
+	// has no filters
+	Analyzer analysis.Analyzer
+}
+
+func DefaultOptions() Options {
+	return Options{Length: DEFAULT_LENGTH, Fragments: DEFAULT_FRAGMENTS, Analyzer: analysis.Default}
+}
+
+// fragment is a part of the text between two byte offsets.
+type fragment struct {
+	start, end int
+	// Whether there's more text after the fragment
+	more bool
+	// Tokens that match keywords
+	matches []analysis.Token
+	score   int
+}
+
+// Make returns an HTML snippet of a text with keywords highlighted. The
+// snippet consists of fragments of the text that contain the most keywords.
+// If none of the keywords are in the text, the beginning of the text is used.
+func Make(text string, keywords []index.Keyword, options Options) string {
+	if options.Length <= 0 {
+		options.Length = DEFAULT_LENGTH
+	}
+	if options.Fragments <= 0 {
+		options.Fragments = DEFAULT_FRAGMENTS
+	}
+	if options.Analyzer.Filters == nil {
+		options.Analyzer = analysis.Default
+	}
+	wanted := make(map[index.Keyword]bool, len(keywords))
+	for _, k := range keywords {
+		wanted[k] = true
+	}
+	tokens := options.Analyzer.Analyze(text)
+	matches := make([]int, 0)
+	for n, t := range tokens {
+		if wanted[index.Keyword(t.Text)] {
+			matches = append(matches, n)
+		}
+	}
+
+	candidates := make([]fragment, 0, len(matches))
+	for _, m := range matches {
+		candidates = append(candidates, newFragment(text, tokens, m, options.Length, wanted))
+	}
+	// Fragments with more distinct keywords go first
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].score > candidates[b].score })
+	chosen := make([]fragment, 0, options.Fragments)
+	for _, c := range candidates {
+		if len(chosen) == options.Fragments {
+			break
+		}
+		overlaps := false
+		for _, f := range chosen {
+			if c.start < f.end && f.start < c.end {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			chosen = append(chosen, c)
+		}
+	}
+	if len(chosen) == 0 {
+		chosen = append(chosen, prefix(text, tokens, options.Length))
+	}
+	sort.Slice(chosen, func(a, b int) bool { return chosen[a].start < chosen[b].start })
+
+	var b strings.Builder
+	for n, f := range chosen {
+		if n > 0 {
+			b.WriteString(" " + ELLIPSIS + " ")
+		} else if f.start > 0 {
+			b.WriteString(ELLIPSIS + " ")
+		}
+		b.WriteString(render(text, f))
+		if n == len(chosen)-1 && f.more {
+			b.WriteString(" " + ELLIPSIS)
+		}
+	}
+	return b.String()
+}
+
+// newFragment creates a fragment around a token that matches a keyword. Some
+// words before the match are included to give it context.
+func newFragment(text string, tokens []analysis.Token, match, length int, wanted map[index.Keyword]bool) fragment {
+	first := match
+	for first > 0 && tokens[match].End-tokens[first-1].Start <= length/3 {
+		first--
+	}
+	last := match
+	for last+1 < len(tokens) && tokens[last+1].End-tokens[first].Start <= length {
+		last++
+	}
+	f := fragment{
+		start: punctuationStart(text, tokens[first].Start),
+		end:   punctuationEnd(text, tokens[last].End),
+		more:  last+1 < len(tokens),
+	}
+	found := make(map[string]bool)
+	for _, t := range tokens[first : last+1] {
+		if wanted[index.Keyword(t.Text)] {
+			f.matches = append(f.matches, t)
+			found[t.Text] = true
+		}
+	}
+	// Distinct keywords matter more than repeated ones
+	f.score = len(found)*len(tokens) + len(f.matches)
+	return f
+}
+
+// prefix returns a fragment with tokens from the beginning of the text that
+// fit into the length.
+func prefix(text string, tokens []analysis.Token, length int) fragment {
+	last := -1
+	for last+1 < len(tokens) && tokens[last+1].End <= length {
+		last++
+	}
+	if last < 0 {
+		return fragment{more: len(tokens) > 0}
+	}
+	return fragment{end: punctuationEnd(text, tokens[last].End), more: last+1 < len(tokens)}
+}
+
+// punctuationStart includes punctuation and symbols that precede a word, like
+// an opening parenthesis.
+func punctuationStart(text string, start int) int {
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:start])
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+			break
+		}
+		start -= size
+	}
+	return start
+}
+
+// punctuationEnd includes punctuation and symbols that follow a word, like a
+// full stop.
+func punctuationEnd(text string, end int) int {
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+			break
+		}
+		end += size
+	}
+	return end
+}
+
+// render escapes text of a fragment and highlights its matches. Whitespace is
+// collapsed, since the text consists of lines from different parts of the
+// page.
+func render(text string, f fragment) string {
+	var b strings.Builder
+	pos := f.start
+	for _, m := range f.matches {
+		b.WriteString(html.EscapeString(collapseSpace(text[pos:m.Start])))
+		b.WriteString(HIGHLIGHT_START + html.EscapeString(text[m.Start:m.End]) + HIGHLIGHT_END)
+		pos = m.End
+	}
+	b.WriteString(html.EscapeString(collapseSpace(text[pos:f.end])))
+	return b.String()
+}
+
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
@@ -0,0 +1,38 @@
+package snippet
+
+import (
+	"go.roman.zone/crawl/index"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	text := "Crawlers visit pages.\nA web crawler follows links from one page to another.\n" +
+		"Some other text that doesn't matter at all, but makes the page longer.\n" +
+		"Crawling is important for a <fast> search engine."
+	cases := []struct {
+		keywords []index.Keyword
+		options  Options
+		expected string
+	}{
+		{
+			[]index.Keyword{"web", "crawler"},
+			Options{Length: 40, Fragments: 1},
+			"… pages. A <mark>web</mark> <mark>crawler</mark> follows links from …",
+		},
+		{
+			[]index.Keyword{"crawler", "search"},
+			Options{Length: 40, Fragments: 2},
+			"… A web <mark>crawler</mark> follows links from one … &lt;fast&gt; <mark>search</mark> engine.",
+		},
+		{
+			[]index.Keyword{"missing"},
+			Options{Length: 20, Fragments: 2},
+			"Crawlers visit pages. …",
+		},
+	}
+	for _, c := range cases {
+		if snippet := Make(text, c.keywords, c.options); snippet != c.expected {
+			t.Errorf("Expected %q for %v, got %q", c.expected, c.keywords, snippet)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package suggest corrects misspelled queries and completes partially typed
+// ones using keywords of the index.
+package suggest
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"go.roman.zone/crawl/index"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DEFAULT_LIMIT = 10
+	// Maximum edit distance of corrections. Keywords shorter than
+	// SHORT_KEYWORD_LENGTH can only have one edit, since two edits turn them
+	// into completely different words.
+	MAX_DISTANCE         = 2
+	SHORT_KEYWORD_LENGTH = 5
+)
+
+// Vocabulary provides keywords with their frequencies and the analyzer that
+// produced them. It's implemented by index.Index.
+type Vocabulary interface {
+	Vocabulary(prefix index.Keyword) ([]index.KeywordCount, error)
+	Analyzer() analysis.Analyzer
+}
+
+// Suggestion is a query suggested instead of the typed one.
+type Suggestion struct {
+	Text string
+	// Number of documents with the completed keyword
+	Documents int
+}
+
+// Complete suggests completions of the last word of a query text, most
+// frequent first. Nothing is suggested if the text ends with a space.
+func Complete(v Vocabulary, text string, limit int) ([]Suggestion, error) {
+	if limit <= 0 {
+		limit = DEFAULT_LIMIT
+	}
+	tokens := v.Analyzer().Analyze(text)
+	if len(tokens) == 0 || tokens[len(tokens)-1].End != len(text) {
+		return []Suggestion{}, nil
+	}
+	last := tokens[len(tokens)-1]
+	counts, err := v.Vocabulary(index.Keyword(last.Text))
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(counts, func(i, j int) bool { return counts[i].Documents > counts[j].Documents })
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	suggestions := make([]Suggestion, len(counts))
+	for i, c := range counts {
+		suggestions[i] = Suggestion{Text: text[:last.Start] + string(c.Keyword), Documents: c.Documents}
+	}
+	return suggestions, nil
+}
+
+// DidYouMean replaces keywords of a query text that aren't in the index with
+// the most frequent keywords that are spelled similarly. Operators, field
+// names and keywords with wildcards are kept as they are. It returns an empty
+// string if there's nothing to correct.
+func DidYouMean(v Vocabulary, text string) (string, error) {
+	var b strings.Builder
+	pos := 0
+	corrected := false
+	for _, t := range v.Analyzer().Analyze(text) {
+		if !correctable(text, t) {
+			continue
+		}
+		correction, err := correct(v, index.Keyword(t.Text))
+		if err != nil {
+			return "", err
+		}
+		if correction == "" {
+			continue
+		}
+		b.WriteString(text[pos:t.Start])
+		b.WriteString(string(correction))
+		pos = t.End
+		corrected = true
+	}
+	if !corrected {
+		return "", nil
+	}
+	b.WriteString(text[pos:])
+	return b.String(), nil
+}
+
+// correctable checks if a token of a query is a keyword rather than a part of
+// the query syntax.
+func correctable(text string, t analysis.Token) bool {
+	switch text[t.Start:t.End] {
+	case "AND", "OR", "NOT":
+		return false
+	}
+	if t.End < len(text) && strings.ContainsRune(":*?~", rune(text[t.End])) {
+		return false
+	}
+	if t.Start > 0 && strings.ContainsRune("*?~", rune(text[t.Start-1])) {
+		return false
+	}
+	// Host names of site: aren't keywords
+	wordStart := strings.LastIndexAny(text[:t.Start], " \t\n(") + 1
+	return !strings.HasPrefix(strings.ToLower(text[wordStart:t.Start]), "site:")
+}
+
+// correct returns a keyword to use instead of one that's not in the index, or
+// an empty keyword if the keyword is known or there's nothing similar.
+// Candidates need to have the same first letter, like with fuzzy queries.
+func correct(v Vocabulary, keyword index.Keyword) (index.Keyword, error) {
+	first, _ := utf8.DecodeRuneInString(string(keyword))
+	counts, err := v.Vocabulary(index.Keyword(first))
+	if err != nil {
+		return "", err
+	}
+	maxDistance := MAX_DISTANCE
+	if utf8.RuneCountInString(string(keyword)) < SHORT_KEYWORD_LENGTH {
+		maxDistance = 1
+	}
+	best, bestDistance, bestDocuments := index.Keyword(""), maxDistance+1, 0
+	for _, c := range counts {
+		if c.Keyword == keyword {
+			return "", nil
+		}
+		d := analysis.EditDistance(string(keyword), string(c.Keyword), maxDistance)
+		if d < bestDistance || (d == bestDistance && c.Documents > bestDocuments) {
+			best, bestDistance, bestDocuments = c.Keyword, d, c.Documents
+		}
+	}
+	if bestDistance > maxDistance {
+		return "", nil
+	}
+	return best, nil
+}
@@ -0,0 +1,72 @@
+package suggest
+
+import (
+	"go.roman.zone/crawl/analysis"
+	"go.roman.zone/crawl/index"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func makeIndex(t *testing.T) index.Index {
+	idx := index.NewMemoryIndex(analysis.Default)
+	pages := []string{
+		"web crawler written in go",
+		"crawler for the web",
+		"crawling the web is fun",
+		"crawled pages",
+		"example of a search engine",
+	}
+	for n, content := range pages {
+		page := index.Page{URL: url.URL{Scheme: "https", Host: "example.com", Path: "/" + string(rune('a'+n))}, Content: content}
+		if err := idx.Add(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return idx
+}
+
+func TestComplete(t *testing.T) {
+	idx := makeIndex(t)
+	defer idx.Close()
+	cases := []struct {
+		text     string
+		expected []Suggestion
+	}{
+		{"web Craw", []Suggestion{{"web crawler", 2}, {"web crawled", 1}, {"web crawling", 1}}},
+		{"crawler ", []Suggestion{}},
+		{"zzz", []Suggestion{}},
+	}
+	for _, c := range cases {
+		suggestions, err := Complete(idx, c.text, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(suggestions, c.expected) {
+			t.Errorf("Expected %v for %q, got %v", c.expected, c.text, suggestions)
+		}
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	idx := makeIndex(t)
+	defer idx.Close()
+	cases := map[string]string{
+		"web crawlr":               "web crawler",
+		"serch AND engnie":         "search AND engine",
+		"crawler":                  "",
+		"qwerty":                   "",
+		"title:crawlr":             "title:crawler",
+		"crawl* serch":             "crawl* search",
+		"site:exampel.com crawler": "",
+	}
+	for text, expected := range cases {
+		correction, err := DidYouMean(idx, text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if correction != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, text, correction)
+		}
+	}
+}
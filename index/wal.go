@@ -0,0 +1,160 @@
+package index
+
+import (
+	"encoding/binary"
+	"net/url"
+	"sort"
+	"time"
+)
+
+const LOG_FILE = "wal.log"
+
+type logOperation byte
+
+const (
+	logAdd    logOperation = 'a'
+	logDelete logOperation = 'd'
+	// New fetch time of a page that hasn't changed otherwise
+	logFetch logOperation = 'f'
+)
+
+// logRecord is a change to the index. Only the URL is set in pages of
+// deletions, and the URL and the fetch time in pages of logFetch.
+type logRecord struct {
+	op   logOperation
+	page Page
+}
+
+// writeAheadLog keeps changes that haven't been written into segments yet, so
+// that they can be recovered after a crash. Records are stored in a
+// recordFile.
+type writeAheadLog struct {
+	records *recordFile
+}
+
+// openLog opens a log and calls apply for every record in it.
+func openLog(path string, apply func(r logRecord)) (*writeAheadLog, error) {
+	records, err := openRecordFile(path, func(payload []byte) bool {
+		r, ok := decodeLogRecord(payload)
+		if ok {
+			apply(r)
+		}
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &writeAheadLog{records: records}, nil
+}
+
+// append writes records and syncs the log.
+func (l *writeAheadLog) append(records ...logRecord) error {
+	payloads := make([][]byte, len(records))
+	for n, r := range records {
+		payloads[n] = encodeLogRecord(r)
+	}
+	return l.records.append(payloads...)
+}
+
+// reset removes all records. It's called once the changes are saved
+// elsewhere.
+func (l *writeAheadLog) reset() error {
+	return l.records.reset()
+}
+
+func (l *writeAheadLog) close() error {
+	return l.records.close()
+}
+
+func encodeLogRecord(r logRecord) []byte {
+	b := []byte{byte(r.op)}
+	b = appendString(b, r.page.URL.String())
+	if r.op == logFetch {
+		return appendTime(b, r.page.FetchTime)
+	}
+	if r.op != logAdd {
+		return b
+	}
+	b = appendString(b, r.page.Content)
+	b = appendString(b, r.page.Title)
+	b = appendStrings(b, r.page.Headings)
+	b = appendStrings(b, r.page.Anchors)
+	b = appendString(b, r.page.Language)
+	b = appendTime(b, r.page.FetchTime)
+	b = binary.AppendUvarint(b, uint64(len(r.page.Metadata)))
+	keys := make([]string, 0, len(r.page.Metadata))
+	for k := range r.page.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b = appendString(b, k)
+		b = appendString(b, r.page.Metadata[k])
+	}
+	// Added after the other fields, so that older logs can still be read
+	b = appendString(b, r.page.ContentType)
+	return b
+}
+
+// decodeLogRecord decodes the payload of a record.
+func decodeLogRecord(payload []byte) (logRecord, bool) {
+	var r logRecord
+	r.op = logOperation(payload[0])
+	reader := &byteReader{data: payload[1:]}
+	pageURL, err := url.Parse(reader.string())
+	if err != nil {
+		return r, false
+	}
+	r.page.URL = *pageURL
+	switch r.op {
+	case logDelete:
+	case logFetch:
+		r.page.FetchTime = reader.time()
+	case logAdd:
+		r.page.Content = reader.string()
+		r.page.Title = reader.string()
+		r.page.Headings = reader.strings()
+		r.page.Anchors = reader.strings()
+		r.page.Language = reader.string()
+		r.page.FetchTime = reader.time()
+		metadataCount := reader.uvarint()
+		if reader.err == nil && metadataCount > 0 && metadataCount <= uint64(len(reader.data)) {
+			r.page.Metadata = make(map[string]string, metadataCount)
+			for n := uint64(0); n < metadataCount; n++ {
+				k := reader.string()
+				r.page.Metadata[k] = reader.string()
+			}
+		}
+		if len(reader.data) > 0 {
+			r.page.ContentType = reader.string()
+		}
+	default:
+		return r, false
+	}
+	return r, reader.err == nil
+}
+
+// appendTime writes a time as nanoseconds since the Unix epoch, or 0 for a
+// zero time.
+func appendTime(b []byte, t time.Time) []byte {
+	nanoseconds := int64(0)
+	if !t.IsZero() {
+		nanoseconds = t.UnixNano()
+	}
+	return binary.AppendVarint(b, nanoseconds)
+}
+
+func (r *byteReader) time() time.Time {
+	if nanoseconds := r.varint(); nanoseconds != 0 {
+		return time.Unix(0, nanoseconds).UTC()
+	}
+	return time.Time{}
+}
+
+func appendStrings(b []byte, list []string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(list)))
+	for _, s := range list {
+		b = appendString(b, s)
+	}
+	return b
+}